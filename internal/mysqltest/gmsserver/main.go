@@ -0,0 +1,47 @@
+// Command gmsserver runs an in-memory go-mysql-server for the MySQL tests run without MYSQL_DSN
+//
+// It listens on a free local port, prints the DSN of its root user on the first line of its output,
+// and stops when its standard input is closed, i.e. when the test process that started it exits
+// It lives in a module of its own so that go-mysql-server is not a dependency of the library
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func main() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatalf("listen: %v", err)
+	}
+
+	// Foreign keys need the primary keys to be indexed
+	provider := memory.NewDBProviderWithOpts(memory.NativeIndexProvider(true)).(*memory.DbProvider)
+	engine := sqle.NewDefault(provider)
+	srv, err := server.NewServer(server.Config{
+		Protocol: "tcp",
+		Listener: listener,
+	}, engine, sql.NewContext, memory.NewSessionBuilder(provider), nil)
+	if err != nil {
+		log.Fatalf("create server: %v", err)
+	}
+	go func() {
+		if err := srv.Start(); err != nil {
+			log.Fatalf("serve: %v", err)
+		}
+	}()
+
+	fmt.Printf("root@tcp(%s)/\n", listener.Addr())
+
+	_, _ = io.Copy(io.Discard, os.Stdin)
+	_ = srv.Close()
+}
@@ -0,0 +1,129 @@
+// Package mysqltest provides MySQL databases for tests
+package mysqltest
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// NewDB connects to the server in MYSQL_DSN (e.g. the one started by `make db-up`),
+// or to an in-memory go-mysql-server started by the tests when it is not set, see gmsserver
+// Any MySQL-compatible server works, as long as the user is allowed to create databases
+// The connection works in a new database created from sql/mysql/schema.sql, which is dropped when the test ends
+func NewDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	cfg, name := newDatabase(t)
+
+	ddl, err := os.ReadFile(schemaFile())
+	require.NoError(t, err)
+
+	// The schema holds several statements, which a single Exec only runs when the connection allows it
+	schemaCfg := cfg.Clone()
+	schemaCfg.MultiStatements = true
+	admin, err := openDB(schemaCfg, name)
+	require.NoError(t, err)
+	defer admin.Close()
+	_, err = admin.ExecContext(context.Background(), string(ddl))
+	require.NoError(t, err)
+
+	return connect(t, cfg, name)
+}
+
+// SkipInMemory skips t if it runs on the in-memory go-mysql-server and its name contains one of cases
+// The factories of the conformance suites call it to skip the cases relying on MySQL features that go-mysql-server lacks,
+// described by reason
+func SkipInMemory(t *testing.T, reason string, cases ...string) {
+	t.Helper()
+
+	if os.Getenv("MYSQL_DSN") != "" {
+		return
+	}
+	for _, c := range cases {
+		if strings.Contains(t.Name(), c) {
+			t.Skip("go-mysql-server " + reason)
+		}
+	}
+}
+
+// NewEmptyDB is like NewDB, but returns a connection to a database without tables
+func NewEmptyDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	cfg, name := newDatabase(t)
+	return connect(t, cfg, name)
+}
+
+// newDatabase creates a database on the server in MYSQL_DSN, or on the in-memory one,
+// which is dropped when the test ends
+// It returns the configuration of the server and the name of the database
+func newDatabase(t *testing.T) (*mysql.Config, string) {
+	t.Helper()
+
+	dsn := os.Getenv("MYSQL_DSN")
+	if dsn == "" {
+		var err error
+		dsn, err = inMemoryDSN()
+		require.NoError(t, err, "start go-mysql-server")
+	}
+
+	cfg, err := mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+	name := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+
+	admin, err := openDB(cfg, "")
+	require.NoError(t, err)
+	defer admin.Close()
+
+	_, err = admin.ExecContext(context.Background(), "CREATE DATABASE "+name)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin, err := openDB(cfg, "")
+		if err != nil {
+			return
+		}
+		defer admin.Close()
+		_, _ = admin.ExecContext(context.Background(), "DROP DATABASE "+name)
+	})
+
+	return cfg, name
+}
+
+// connect returns a connection to the given database, closed when the test ends
+func connect(t *testing.T, cfg *mysql.Config, name string) *sql.DB {
+	t.Helper()
+
+	db, err := openDB(cfg, name)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// openDB opens a connection to the given database of the server described by cfg,
+// with the settings of mysqltransaction.Open
+func openDB(cfg *mysql.Config, name string) (*sql.DB, error) {
+	cfg = cfg.Clone()
+	cfg.DBName = name
+	cfg.ParseTime = true
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// schemaFile returns the path of sql/mysql/schema.sql
+func schemaFile() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "sql", "mysql", "schema.sql")
+}
@@ -0,0 +1,65 @@
+package mysqltest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	serverOnce sync.Once
+	serverDSN  string
+	serverErr  error
+
+	// server is the running gmsserver; its standard input stays open until the test process exits
+	server      *exec.Cmd
+	serverStdin io.WriteCloser
+)
+
+// inMemoryDSN starts gmsserver, an in-memory go-mysql-server, on first use and returns the DSN of its root user
+// The server runs in a child process built from a module of its own, and stops when the test process exits
+func inMemoryDSN() (string, error) {
+	serverOnce.Do(func() {
+		cmd := exec.Command("go", "run", ".")
+		cmd.Dir = serverDir()
+		cmd.Stderr = os.Stderr
+
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			serverErr = err
+			return
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			serverErr = err
+			return
+		}
+		if err := cmd.Start(); err != nil {
+			serverErr = err
+			return
+		}
+
+		line, err := bufio.NewReader(stdout).ReadString('\n')
+		if err != nil {
+			_ = cmd.Process.Kill()
+			serverErr = fmt.Errorf("read the DSN of gmsserver: %w", err)
+			return
+		}
+
+		server, serverStdin = cmd, stdin
+		serverDSN = strings.TrimSpace(line)
+	})
+	return serverDSN, serverErr
+}
+
+// serverDir returns the path of the gmsserver module
+func serverDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "gmsserver")
+}
@@ -0,0 +1,79 @@
+// Package pgtest provides PostgreSQL databases for tests
+package pgtest
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/require"
+)
+
+// NewPool connects to the database in DATABASE_URL (e.g. the one started by `make db-up`)
+// and skips the test when it is not set
+// The pool works in a new schema created from sql/schema.sql, which is dropped when the test ends
+func NewPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	schema := pgx.Identifier{"test_" + strings.ReplaceAll(uuid.NewString(), "-", "")}.Sanitize()
+
+	admin, err := pgx.Connect(ctx, url)
+	require.NoError(t, err)
+	defer admin.Close(ctx)
+
+	_, err = admin.Exec(ctx, "CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn, err := pgx.Connect(context.Background(), url)
+		if err != nil {
+			return
+		}
+		defer conn.Close(context.Background())
+		_, _ = conn.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+	})
+
+	config, err := pgxpool.ParseConfig(url)
+	require.NoError(t, err)
+	// public stays in the path for the uuid-ossp functions
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ", public"
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	ddl, err := os.ReadFile(schemaFile())
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, string(ddl))
+	require.NoError(t, err)
+
+	return pool
+}
+
+// NewDB is like NewPool, but returns a *sql.DB using the pgx stdlib driver
+func NewDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db := stdlib.OpenDBFromPool(NewPool(t))
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// schemaFile returns the path of sql/schema.sql
+func schemaFile() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "sql", "schema.sql")
+}
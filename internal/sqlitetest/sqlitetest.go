@@ -0,0 +1,37 @@
+// Package sqlitetest provides SQLite databases for tests
+package sqlitetest
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/sqlitetransaction"
+	"github.com/stretchr/testify/require"
+)
+
+// NewDB returns a database in a temporary file created from sql/sqlite/schema.sql
+// The file is removed when the test ends
+func NewDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sqlitetransaction.Open(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	ddl, err := os.ReadFile(schemaFile())
+	require.NoError(t, err)
+	_, err = db.ExecContext(context.Background(), string(ddl))
+	require.NoError(t, err)
+
+	return db
+}
+
+// schemaFile returns the path of sql/sqlite/schema.sql
+func schemaFile() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "sql", "sqlite", "schema.sql")
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is returned when the requested entity does not exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidReference is returned when an entity refers to another one that does not exist,
+	// or when deleting an entity that is still referred to
+	ErrInvalidReference = errors.New("invalid reference")
+
+	// ErrInvalidInput is returned when a value breaks a constraint of the entity, such as a required field or a maximum length
+	ErrInvalidInput = errors.New("invalid input")
+)
+
+// ErrConflict is returned when a value that must be unique is already used by another entity
+type ErrConflict struct {
+	// Field is the name of the field holding the value
+	Field string
+
+	// Err is the underlying error, if any
+	Err error
+}
+
+func (e *ErrConflict) Error() string {
+	if e.Field == "" {
+		return "value already in use"
+	}
+	return fmt.Sprintf("%s already in use", e.Field)
+}
+
+func (e *ErrConflict) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *ErrConflict on the same field
+// A target without a field matches any conflict
+func (e *ErrConflict) Is(target error) bool {
+	t, ok := target.(*ErrConflict)
+	return ok && (t.Field == "" || t.Field == e.Field)
+}
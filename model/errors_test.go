@@ -0,0 +1,49 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrConflict_Is(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		target   error
+		expected bool
+	}{
+		"same field": {
+			err:      &ErrConflict{Field: "email", Err: errors.New("duplicate key")},
+			target:   &ErrConflict{Field: "email"},
+			expected: true,
+		},
+		"wrapped": {
+			err:      fmt.Errorf("create user: %w", &ErrConflict{Field: "email"}),
+			target:   &ErrConflict{Field: "email"},
+			expected: true,
+		},
+		"any field": {
+			err:      &ErrConflict{Field: "email"},
+			target:   &ErrConflict{},
+			expected: true,
+		},
+		"other field": {
+			err:      &ErrConflict{Field: "email"},
+			target:   &ErrConflict{Field: "name"},
+			expected: false,
+		},
+		"other error": {
+			err:      ErrNotFound,
+			target:   &ErrConflict{},
+			expected: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, errors.Is(tt.err, tt.target))
+		})
+	}
+}
@@ -14,8 +14,8 @@ import (
 type Querier interface {
 	CreatePost(ctx context.Context, arg CreatePostParams) (Post, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeletePost(ctx context.Context, id uuid.UUID) error
-	DeleteUser(ctx context.Context, id uuid.UUID) error
+	DeletePost(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteUser(ctx context.Context, id uuid.UUID) (int64, error)
 	GetPost(ctx context.Context, id uuid.UUID) (Post, error)
 	GetUser(ctx context.Context, id uuid.UUID) (User, error)
 	ListPostsByUser(ctx context.Context, userID pgtype.UUID) ([]Post, error)
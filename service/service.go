@@ -8,6 +8,7 @@ import (
 	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
 	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
 	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/google/uuid"
 )
 
 // Service represents the application service layer
@@ -28,25 +29,20 @@ func New(txManager transaction.Manager, userStore userstore.Store, postStore pos
 
 // CreateUserWithPost creates a user and a post in a single transaction
 func (s *Service) CreateUserWithPost(ctx context.Context, name, email, postTitle, postContent string) (*model.User, *model.Post, error) {
-	var user model.User
-	var post model.Post
-
-	err := s.txManager.ExecTx(ctx, func(ctx context.Context) error {
-		var err error
-
+	user, post, err := transaction.Do2(ctx, s.txManager, func(ctx context.Context) (model.User, model.Post, error) {
 		// Create user
-		user, err = s.userStore.CreateUser(ctx, name, email)
+		user, err := s.userStore.CreateUser(ctx, name, email)
 		if err != nil {
-			return fmt.Errorf("failed to create user: %w", err)
+			return model.User{}, model.Post{}, fmt.Errorf("failed to create user: %w", err)
 		}
 
 		// Create post
-		post, err = s.postStore.CreatePost(ctx, user.ID, postTitle, postContent)
+		post, err := s.postStore.CreatePost(ctx, user.ID, postTitle, postContent)
 		if err != nil {
-			return fmt.Errorf("failed to create post: %w", err)
+			return model.User{}, model.Post{}, fmt.Errorf("failed to create post: %w", err)
 		}
 
-		return nil
+		return user, post, nil
 	})
 
 	if err != nil {
@@ -55,3 +51,26 @@ func (s *Service) CreateUserWithPost(ctx context.Context, name, email, postTitle
 
 	return &user, &post, nil
 }
+
+// GetUserWithPosts retrieves a user and their posts, newest first, from a single read-only snapshot
+func (s *Service) GetUserWithPosts(ctx context.Context, userID uuid.UUID) (*model.User, []model.Post, error) {
+	user, posts, err := transaction.Do2ReadOnly(ctx, s.txManager, func(ctx context.Context) (model.User, []model.Post, error) {
+		user, err := s.userStore.GetUser(ctx, userID)
+		if err != nil {
+			return model.User{}, nil, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		posts, err := s.postStore.ListPostsByUser(ctx, userID)
+		if err != nil {
+			return model.User{}, nil, fmt.Errorf("failed to list posts: %w", err)
+		}
+
+		return user, posts, nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &user, posts, nil
+}
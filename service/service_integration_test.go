@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/mysqltest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/pgtest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/sqlitetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/db"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/mysqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqlitedb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/postmysqlstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/postpgstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/postsqlitestore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/postsqlstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usermysqlstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/userpgstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usersqlitestore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usersqlstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/mysqltransaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/pgxtransaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/sqlitetransaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/sqltransaction"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backends builds the service on top of each database backend
+// The PostgreSQL ones use the database in DATABASE_URL (e.g. the one started by `make db-up`)
+// and are skipped when it is not set
+// The MySQL one uses the server in MYSQL_DSN, or the in-memory go-mysql-server of mysqltest when it is not set
+var backends = map[string]func(t *testing.T) (*Service, userstore.Store){
+	"pgx": func(t *testing.T) (*Service, userstore.Store) {
+		pool := pgtest.NewPool(t)
+		queries := db.New(pool)
+		userStore := userpgstore.New(queries)
+		return New(pgxtransaction.New(pool), userStore, postpgstore.New(queries)), userStore
+	},
+	"pgx cluster": func(t *testing.T) (*Service, userstore.Store) {
+		// The primary doubles as the replica, which is enough to exercise the routing
+		pool := pgtest.NewPool(t)
+		cluster := pgxtransaction.NewCluster(pool, []*pgxpool.Pool{pool})
+		queries := db.New(cluster)
+		userStore := userpgstore.New(queries)
+		return New(pgxtransaction.NewWithCluster(cluster), userStore, postpgstore.New(queries)), userStore
+	},
+	"database/sql": func(t *testing.T) (*Service, userstore.Store) {
+		sqlDB := pgtest.NewDB(t)
+		queries := sqldb.New(sqlDB)
+		userStore := usersqlstore.New(queries)
+		return New(sqltransaction.New(sqlDB), userStore, postsqlstore.New(queries)), userStore
+	},
+	"sqlite": func(t *testing.T) (*Service, userstore.Store) {
+		sqlDB := sqlitetest.NewDB(t)
+		queries := sqlitedb.New(sqlDB)
+		userStore := usersqlitestore.New(queries)
+		return New(sqlitetransaction.New(sqlDB), userStore, postsqlitestore.New(queries)), userStore
+	},
+	"mysql": func(t *testing.T) (*Service, userstore.Store) {
+		sqlDB := mysqltest.NewDB(t)
+		queries := mysqldb.New(sqlDB)
+		userStore := usermysqlstore.New(queries)
+		return New(mysqltransaction.New(sqlDB), userStore, postmysqlstore.New(queries)), userStore
+	},
+}
+
+func TestCreateUserWithPost_Integration(t *testing.T) {
+	tests := map[string]struct {
+		postTitle     string
+		expectedError assert.ErrorAssertionFunc
+		expectedUser  bool
+	}{
+		"success - user and post are committed together": {
+			postTitle:     "Test Title",
+			expectedError: assert.NoError,
+			expectedUser:  true,
+		},
+		"error - failing post creation rolls back the user": {
+			// posts.title is limited to 255 characters
+			postTitle:     strings.Repeat("a", 256),
+			expectedError: assert.Error,
+			expectedUser:  false,
+		},
+	}
+
+	for backend, newService := range backends {
+		for name, tt := range tests {
+			t.Run(backend+"/"+name, func(t *testing.T) {
+				svc, userStore := newService(t)
+				ctx := context.Background()
+				email := uuid.NewString() + "@example.com"
+
+				_, _, err := svc.CreateUserWithPost(ctx, "Test User", email, tt.postTitle, "Test Content")
+				tt.expectedError(t, err)
+
+				users, err := userStore.ListUsers(ctx)
+				require.NoError(t, err)
+
+				found := false
+				for _, u := range users {
+					if u.Email == email {
+						found = true
+					}
+				}
+				assert.Equal(t, tt.expectedUser, found)
+			})
+		}
+	}
+}
+
+func TestGetUserWithPosts_Integration(t *testing.T) {
+	for backend, newService := range backends {
+		t.Run(backend, func(t *testing.T) {
+			svc, _ := newService(t)
+			ctx := context.Background()
+			email := uuid.NewString() + "@example.com"
+
+			created, post, err := svc.CreateUserWithPost(ctx, "Test User", email, "Test Title", "Test Content")
+			require.NoError(t, err)
+
+			user, posts, err := svc.GetUserWithPosts(ctx, created.ID)
+			require.NoError(t, err)
+			assert.Equal(t, created.ID, user.ID)
+			if assert.Len(t, posts, 1) {
+				assert.Equal(t, post.ID, posts[0].ID)
+			}
+
+			_, _, err = svc.GetUserWithPosts(ctx, uuid.New())
+			assert.ErrorIs(t, err, model.ErrNotFound)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/postmemorystore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/posttracestore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usermemorystore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usertracestore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/memtransaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txtrace"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errPostRejected = errors.New("post rejected")
+
+// rejectingPostStore writes posts, then fails when their title is rejected
+type rejectingPostStore struct {
+	poststore.Store
+	rejected string
+}
+
+func (s *rejectingPostStore) CreatePost(ctx context.Context, userID uuid.UUID, title, content string) (model.Post, error) {
+	post, err := s.Store.CreatePost(ctx, userID, title, content)
+	if err != nil {
+		return model.Post{}, err
+	}
+	if title == s.rejected {
+		return model.Post{}, errPostRejected
+	}
+	return post, nil
+}
+
+func TestCreateUserWithPost_Memory(t *testing.T) {
+	tests := map[string]struct {
+		postTitle     string
+		expectedError error
+		expectedUsers int
+	}{
+		"success - user and post are committed together": {
+			postTitle:     "Test Title",
+			expectedUsers: 1,
+		},
+		"error - failing post creation rolls back the user and the post": {
+			postTitle:     "rejected",
+			expectedError: errPostRejected,
+			expectedUsers: 0,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			userStore := usermemorystore.New()
+			postStore := postmemorystore.New(userStore)
+			svc := New(memtransaction.New(), userStore, &rejectingPostStore{Store: postStore, rejected: "rejected"})
+
+			_, _, err := svc.CreateUserWithPost(ctx, "Test User", "test@example.com", tt.postTitle, "Test Content")
+			assert.ErrorIs(t, err, tt.expectedError)
+
+			users, err := userStore.ListUsers(ctx)
+			require.NoError(t, err)
+			require.Len(t, users, tt.expectedUsers)
+
+			for _, user := range users {
+				posts, err := postStore.ListPostsByUser(ctx, user.ID)
+				require.NoError(t, err)
+				assert.Len(t, posts, 1)
+			}
+		})
+	}
+}
+
+func TestCreateUserWithPost_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	userStore := usermemorystore.New()
+	svc := New(
+		txtrace.New(memtransaction.New(), tp),
+		usertracestore.New(userStore, tp),
+		posttracestore.New(postmemorystore.New(userStore), tp),
+	)
+
+	_, _, err := svc.CreateUserWithPost(context.Background(), "Test User", "test@example.com", "Test Title", "Test Content")
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	tx := spans[2]
+	assert.Equal(t, "transaction", tx.Name)
+	for _, span := range spans[:2] {
+		assert.Equal(t, tx.SpanContext.SpanID(), span.Parent.SpanID(), "%s should be a child of the transaction", span.Name)
+	}
+	assert.Equal(t, "userstore.CreateUser", spans[0].Name)
+	assert.Equal(t, "poststore.CreatePost", spans[1].Name)
+}
@@ -162,3 +162,123 @@ func TestCreateUserWithPost(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUserWithPosts(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now()
+
+	testUser := model.User{
+		ID:        userID,
+		Name:      "Test User",
+		Email:     "test@example.com",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	testPosts := []model.Post{
+		{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Title:     "Test Title",
+			Content:   "Test Content",
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	tests := map[string]struct {
+		setupMocks      func(mockTx *txmocks.MockManager, mockUserStore *usermocks.MockStore, mockPostStore *postmocks.MockStore)
+		expectedUser    *model.User
+		expectedPosts   []model.Post
+		expectedError   assert.ErrorAssertionFunc
+		expectedErrText string
+	}{
+		"success - user and posts retrieved in a read-only transaction": {
+			setupMocks: func(mockTx *txmocks.MockManager, mockUserStore *usermocks.MockStore, mockPostStore *postmocks.MockStore) {
+				mockTx.EXPECT().
+					ExecReadOnly(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+						return fn(ctx)
+					})
+
+				mockUserStore.EXPECT().
+					GetUser(gomock.Any(), userID).
+					Return(testUser, nil)
+
+				mockPostStore.EXPECT().
+					ListPostsByUser(gomock.Any(), userID).
+					Return(testPosts, nil)
+			},
+			expectedUser:  &testUser,
+			expectedPosts: testPosts,
+			expectedError: assert.NoError,
+		},
+		"error - user retrieval fails": {
+			setupMocks: func(mockTx *txmocks.MockManager, mockUserStore *usermocks.MockStore, mockPostStore *postmocks.MockStore) {
+				mockTx.EXPECT().
+					ExecReadOnly(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+						return fn(ctx)
+					})
+
+				mockUserStore.EXPECT().
+					GetUser(gomock.Any(), userID).
+					Return(model.User{}, model.ErrNotFound)
+			},
+			expectedError:   assert.Error,
+			expectedErrText: "failed to get user",
+		},
+		"error - post listing fails": {
+			setupMocks: func(mockTx *txmocks.MockManager, mockUserStore *usermocks.MockStore, mockPostStore *postmocks.MockStore) {
+				mockTx.EXPECT().
+					ExecReadOnly(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+						return fn(ctx)
+					})
+
+				mockUserStore.EXPECT().
+					GetUser(gomock.Any(), userID).
+					Return(testUser, nil)
+
+				mockPostStore.EXPECT().
+					ListPostsByUser(gomock.Any(), userID).
+					Return(nil, errors.New("post listing failed"))
+			},
+			expectedError:   assert.Error,
+			expectedErrText: "failed to list posts",
+		},
+		"error - transaction execution fails": {
+			setupMocks: func(mockTx *txmocks.MockManager, mockUserStore *usermocks.MockStore, mockPostStore *postmocks.MockStore) {
+				mockTx.EXPECT().
+					ExecReadOnly(gomock.Any(), gomock.Any()).
+					Return(errors.New("transaction error"))
+			},
+			expectedError:   assert.Error,
+			expectedErrText: "transaction error",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockTx := txmocks.NewMockManager(ctrl)
+			mockUserStore := usermocks.NewMockStore(ctrl)
+			mockPostStore := postmocks.NewMockStore(ctrl)
+
+			tt.setupMocks(mockTx, mockUserStore, mockPostStore)
+
+			svc := New(mockTx, mockUserStore, mockPostStore)
+
+			user, posts, err := svc.GetUserWithPosts(context.Background(), userID)
+
+			tt.expectedError(t, err)
+			if tt.expectedErrText != "" {
+				assert.Contains(t, err.Error(), tt.expectedErrText)
+			}
+			assert.Equal(t, tt.expectedUser, user)
+			assert.Equal(t, tt.expectedPosts, posts)
+		})
+	}
+}
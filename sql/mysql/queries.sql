@@ -0,0 +1,57 @@
+-- name: GetUser :one
+SELECT * FROM users
+WHERE id = ? LIMIT 1;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY name;
+
+-- name: CreateUser :exec
+INSERT INTO users (
+  id,
+  name,
+  email
+) VALUES (
+  ?, ?, ?
+);
+
+-- name: UpdateUser :exec
+UPDATE users
+SET name = ?,
+    email = ?,
+    updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ?;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = ?;
+
+-- name: GetPost :one
+SELECT * FROM posts
+WHERE id = ? LIMIT 1;
+
+-- name: ListPostsByUser :many
+SELECT * FROM posts
+WHERE user_id = ?
+ORDER BY created_at DESC;
+
+-- name: CreatePost :exec
+INSERT INTO posts (
+  id,
+  user_id,
+  title,
+  content
+) VALUES (
+  ?, ?, ?, ?
+);
+
+-- name: UpdatePost :exec
+UPDATE posts
+SET title = ?,
+    content = ?,
+    updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ?;
+
+-- name: DeletePost :execrows
+DELETE FROM posts
+WHERE id = ?;
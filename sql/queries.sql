@@ -23,7 +23,7 @@ SET name = $2,
 WHERE id = $1
 RETURNING *;
 
--- name: DeleteUser :exec
+-- name: DeleteUser :execrows
 DELETE FROM users
 WHERE id = $1;
 
@@ -54,6 +54,6 @@ SET title = $2,
 WHERE id = $1
 RETURNING *;
 
--- name: DeletePost :exec
+-- name: DeletePost :execrows
 DELETE FROM posts
 WHERE id = $1; 
@@ -0,0 +1,61 @@
+-- name: GetUser :one
+SELECT * FROM users
+WHERE id = ? LIMIT 1;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY name;
+
+-- name: CreateUser :one
+INSERT INTO users (
+  id,
+  name,
+  email
+) VALUES (
+  ?, ?, ?
+)
+RETURNING *;
+
+-- name: UpdateUser :one
+UPDATE users
+SET name = ?,
+    email = ?,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = ?
+RETURNING *;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = ?;
+
+-- name: GetPost :one
+SELECT * FROM posts
+WHERE id = ? LIMIT 1;
+
+-- name: ListPostsByUser :many
+SELECT * FROM posts
+WHERE user_id = ?
+ORDER BY created_at DESC;
+
+-- name: CreatePost :one
+INSERT INTO posts (
+  id,
+  user_id,
+  title,
+  content
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: UpdatePost :one
+UPDATE posts
+SET title = ?,
+    content = ?,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+WHERE id = ?
+RETURNING *;
+
+-- name: DeletePost :execrows
+DELETE FROM posts
+WHERE id = ?;
@@ -0,0 +1,20 @@
+-- SQLite equivalent of sql/schema.sql
+-- UUIDs are stored as TEXT and generated by the application; timestamps are UTC with millisecond precision
+-- The CHECK constraints stand for the VARCHAR lengths, which SQLite does not enforce
+
+CREATE TABLE users (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL CHECK (length(name) <= 100),
+  email TEXT NOT NULL UNIQUE CHECK (length(email) <= 255),
+  created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+  updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE posts (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL REFERENCES users(id),
+  title TEXT NOT NULL CHECK (length(title) <= 255),
+  content TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+  updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
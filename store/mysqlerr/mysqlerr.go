@@ -0,0 +1,68 @@
+// Package mysqlerr translates the errors returned by MySQL into the domain errors of the model package
+package mysqlerr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/go-sql-driver/mysql"
+)
+
+// Server error numbers of the errors translated by Translate
+const (
+	erBadNullError            = 1048
+	erDupEntry                = 1062
+	erDataTooLong             = 1406
+	erRowIsReferenced2        = 1451
+	erNoReferencedRow2        = 1452
+	erCheckConstraintViolated = 3819
+)
+
+// duplicateMessage extracts the key from the message of a duplicate entry,
+// e.g. "Duplicate entry 'a@example.com' for key 'users.email'" (MySQL 8) or "... for key 'email'" (MySQL 5.7)
+var duplicateMessage = regexp.MustCompile(`for key '(?:\w+\.)?(\w+)'$`)
+
+// Translate converts err into a domain error, keeping err in the chain:
+//   - sql.ErrNoRows becomes model.ErrNotFound
+//   - duplicate entries become a *model.ErrConflict on the violated key
+//   - foreign key violations, on either side of the reference, become model.ErrInvalidReference
+//   - null values in NOT NULL columns, values too long for their column and check violations become model.ErrInvalidInput
+//
+// Other errors are returned unchanged
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", model.ErrNotFound, err)
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return err
+	}
+
+	switch mysqlErr.Number {
+	case erDupEntry:
+		return &model.ErrConflict{Field: conflictField(mysqlErr), Err: err}
+	case erNoReferencedRow2, erRowIsReferenced2:
+		return fmt.Errorf("%w: %w", model.ErrInvalidReference, err)
+	case erBadNullError, erDataTooLong, erCheckConstraintViolated:
+		return fmt.Errorf("%w: %w", model.ErrInvalidInput, err)
+	default:
+		return err
+	}
+}
+
+// conflictField returns the key of a duplicate entry, which is named after its column for single-column unique keys
+// The primary key is reported as "PRIMARY", and the field is left empty when the message cannot be parsed
+func conflictField(err *mysql.MySQLError) string {
+	if m := duplicateMessage.FindStringSubmatch(err.Message); m != nil {
+		return m[1]
+	}
+	return ""
+}
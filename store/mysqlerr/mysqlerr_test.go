@@ -0,0 +1,83 @@
+package mysqlerr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslate(t *testing.T) {
+	errOther := errors.New("connection refused")
+	errDeadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+
+	tests := map[string]struct {
+		err         error
+		expectedErr error
+	}{
+		"no rows": {
+			err:         fmt.Errorf("get user: %w", sql.ErrNoRows),
+			expectedErr: model.ErrNotFound,
+		},
+		"duplicate entry": {
+			err:         &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'alice@example.com' for key 'users.email'"},
+			expectedErr: &model.ErrConflict{Field: "email"},
+		},
+		"duplicate entry before MySQL 8": {
+			err:         &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'alice@example.com' for key 'email'"},
+			expectedErr: &model.ErrConflict{Field: "email"},
+		},
+		"duplicate primary key": {
+			err:         &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'users.PRIMARY'"},
+			expectedErr: &model.ErrConflict{Field: "PRIMARY"},
+		},
+		"duplicate entry with unexpected message": {
+			err:         &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"},
+			expectedErr: &model.ErrConflict{},
+		},
+		"missing referenced row": {
+			err:         &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails"},
+			expectedErr: model.ErrInvalidReference,
+		},
+		"row still referenced": {
+			err:         &mysql.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row: a foreign key constraint fails"},
+			expectedErr: model.ErrInvalidReference,
+		},
+		"null value": {
+			err:         &mysql.MySQLError{Number: 1048, Message: "Column 'name' cannot be null"},
+			expectedErr: model.ErrInvalidInput,
+		},
+		"value too long": {
+			err:         &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'title' at row 1"},
+			expectedErr: model.ErrInvalidInput,
+		},
+		"check violation": {
+			err:         &mysql.MySQLError{Number: 3819, Message: "Check constraint 'users_chk_1' is violated."},
+			expectedErr: model.ErrInvalidInput,
+		},
+		"other mysql error": {
+			err:         errDeadlock,
+			expectedErr: errDeadlock,
+		},
+		"other error": {
+			err:         errOther,
+			expectedErr: errOther,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Translate(tt.err)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			// The original error stays in the chain
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	assert.NoError(t, Translate(nil))
+}
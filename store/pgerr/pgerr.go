@@ -0,0 +1,73 @@
+// Package pgerr translates the errors returned by PostgreSQL into the domain errors of the model package
+package pgerr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE codes of the errors translated by Translate
+const (
+	stringDataRightTruncation = "22001"
+	notNullViolation          = "23502"
+	foreignKeyViolation       = "23503"
+	uniqueViolation           = "23505"
+	checkViolation            = "23514"
+)
+
+// keyDetail extracts the columns from the detail of a unique violation, e.g. "Key (email)=(a@example.com) already exists."
+var keyDetail = regexp.MustCompile(`^Key \((.+?)\)=`)
+
+// Translate converts err into a domain error, keeping err in the chain:
+//   - pgx.ErrNoRows and sql.ErrNoRows become model.ErrNotFound
+//   - unique violations become a *model.ErrConflict on the violated column
+//   - foreign key violations become model.ErrInvalidReference
+//   - not-null and check violations, and values too long for their column, become model.ErrInvalidInput
+//
+// Errors are recognized by their SQLSTATE, exposed through a SQLState() method by pgx, pgx stdlib and lib/pq
+// Other errors are returned unchanged
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", model.ErrNotFound, err)
+	}
+
+	var sqlErr interface{ SQLState() string }
+	if !errors.As(err, &sqlErr) {
+		return err
+	}
+
+	switch sqlErr.SQLState() {
+	case uniqueViolation:
+		return &model.ErrConflict{Field: conflictField(err), Err: err}
+	case foreignKeyViolation:
+		return fmt.Errorf("%w: %w", model.ErrInvalidReference, err)
+	case notNullViolation, checkViolation, stringDataRightTruncation:
+		return fmt.Errorf("%w: %w", model.ErrInvalidInput, err)
+	default:
+		return err
+	}
+}
+
+// conflictField returns the columns of a unique violation, or the constraint name when the detail is not available
+// The field is left empty for drivers other than pgx
+func conflictField(err error) string {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return ""
+	}
+
+	if m := keyDetail.FindStringSubmatch(pgErr.Detail); m != nil {
+		return m[1]
+	}
+	return pgErr.ConstraintName
+}
@@ -0,0 +1,90 @@
+package pgerr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// sqlStateError stands for the errors of drivers other than pgx, such as *pq.Error
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "pq: " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestTranslate(t *testing.T) {
+	errOther := errors.New("connection refused")
+	errSerialization := &pgconn.PgError{Code: "40001"}
+
+	tests := map[string]struct {
+		err         error
+		expectedErr error
+	}{
+		"no rows": {
+			err:         fmt.Errorf("get user: %w", pgx.ErrNoRows),
+			expectedErr: model.ErrNotFound,
+		},
+		"sql no rows": {
+			err:         sql.ErrNoRows,
+			expectedErr: model.ErrNotFound,
+		},
+		"unique violation": {
+			err: &pgconn.PgError{
+				Code:           "23505",
+				ConstraintName: "users_email_key",
+				Detail:         "Key (email)=(alice@example.com) already exists.",
+			},
+			expectedErr: &model.ErrConflict{Field: "email"},
+		},
+		"unique violation without detail": {
+			err:         &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"},
+			expectedErr: &model.ErrConflict{Field: "users_email_key"},
+		},
+		"unique violation from another driver": {
+			err:         sqlStateError("23505"),
+			expectedErr: &model.ErrConflict{},
+		},
+		"foreign key violation": {
+			err:         &pgconn.PgError{Code: "23503", ConstraintName: "posts_user_id_fkey"},
+			expectedErr: model.ErrInvalidReference,
+		},
+		"not-null violation": {
+			err:         &pgconn.PgError{Code: "23502", ColumnName: "name"},
+			expectedErr: model.ErrInvalidInput,
+		},
+		"check violation": {
+			err:         &pgconn.PgError{Code: "23514"},
+			expectedErr: model.ErrInvalidInput,
+		},
+		"value too long": {
+			err:         &pgconn.PgError{Code: "22001"},
+			expectedErr: model.ErrInvalidInput,
+		},
+		"other postgres error": {
+			err:         errSerialization,
+			expectedErr: errSerialization,
+		},
+		"other error": {
+			err:         errOther,
+			expectedErr: errOther,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Translate(tt.err)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			// The original error stays in the chain
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	assert.NoError(t, Translate(nil))
+}
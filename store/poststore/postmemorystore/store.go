@@ -3,35 +3,56 @@ package postmemorystore
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
 	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usermemorystore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/memtransaction"
 	"github.com/google/uuid"
 )
 
 var (
-	ErrPostNotFound = errors.New("post not found")
+	// ErrPostNotFound is returned when no post has the requested ID
+	//
+	// Deprecated: use poststore.ErrNotFound
+	ErrPostNotFound = poststore.ErrNotFound
 )
 
+// Maximum length of the title column of the posts table
+const maxTitleLength = 255
+
 type memoryStore struct {
 	mu    sync.RWMutex
 	posts map[uuid.UUID]model.Post
+	users userstore.Store
 }
 
 // New creates a new in-memory implementation of poststore.Store
-func New() poststore.Store {
-	return &memoryStore{
+// users is used to check that the user of a new post exists, like the foreign key of the posts table
+// If users is a usermemorystore.GuardedStore, deleting a user who still has posts fails with model.ErrInvalidReference as well
+func New(users userstore.Store) poststore.Store {
+	s := &memoryStore{
 		posts: make(map[uuid.UUID]model.Post),
+		users: users,
+	}
+	if guarded, ok := users.(usermemorystore.GuardedStore); ok {
+		guarded.AddDeleteGuard(s.checkNoPosts)
 	}
+	return s
 }
 
-func (s *memoryStore) CreatePost(_ context.Context, userID uuid.UUID, title, content string) (model.Post, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *memoryStore) CreatePost(ctx context.Context, userID uuid.UUID, title, content string) (model.Post, error) {
+	if err := validate(title); err != nil {
+		return model.Post{}, err
+	}
 
-	now := time.Now()
+	now := currentTime()
 	post := model.Post{
 		ID:        uuid.New(),
 		UserID:    userID,
@@ -41,7 +62,21 @@ func (s *memoryStore) CreatePost(_ context.Context, userID uuid.UUID, title, con
 		UpdatedAt: now,
 	}
 
-	s.posts[post.ID] = post
+	err := s.withUser(ctx, userID, func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if err := memtransaction.RecordUndo(ctx, s.restore(post.ID, model.Post{}, false)); err != nil {
+			return err
+		}
+
+		s.posts[post.ID] = post
+		return nil
+	})
+	if err != nil {
+		return model.Post{}, err
+	}
+
 	return post, nil
 }
 
@@ -51,7 +86,7 @@ func (s *memoryStore) GetPost(_ context.Context, id uuid.UUID) (model.Post, erro
 
 	post, exists := s.posts[id]
 	if !exists {
-		return model.Post{}, ErrPostNotFound
+		return model.Post{}, poststore.ErrNotFound
 	}
 
 	return post, nil
@@ -61,41 +96,118 @@ func (s *memoryStore) ListPostsByUser(_ context.Context, userID uuid.UUID) ([]mo
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	var result []model.Post
+	result := []model.Post{}
 	for _, post := range s.posts {
 		if post.UserID == userID {
 			result = append(result, post)
 		}
 	}
 
+	// Newest first
+	slices.SortFunc(result, func(a, b model.Post) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
 	return result, nil
 }
 
-func (s *memoryStore) UpdatePost(_ context.Context, id uuid.UUID, title, content string) (model.Post, error) {
+func (s *memoryStore) UpdatePost(ctx context.Context, id uuid.UUID, title, content string) (model.Post, error) {
+	if err := validate(title); err != nil {
+		return model.Post{}, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	post, exists := s.posts[id]
 	if !exists {
-		return model.Post{}, ErrPostNotFound
+		return model.Post{}, poststore.ErrNotFound
+	}
+
+	if err := memtransaction.RecordUndo(ctx, s.restore(id, post, true)); err != nil {
+		return model.Post{}, err
 	}
 
 	post.Title = title
 	post.Content = content
-	post.UpdatedAt = time.Now()
+	post.UpdatedAt = currentTime()
 
 	s.posts[id] = post
 	return post, nil
 }
 
-func (s *memoryStore) DeletePost(_ context.Context, id uuid.UUID) error {
+func (s *memoryStore) DeletePost(ctx context.Context, id uuid.UUID) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.posts[id]; !exists {
-		return ErrPostNotFound
+	post, exists := s.posts[id]
+	if !exists {
+		return poststore.ErrNotFound
+	}
+
+	if err := memtransaction.RecordUndo(ctx, s.restore(id, post, true)); err != nil {
+		return err
 	}
 
 	delete(s.posts, id)
 	return nil
 }
+
+// validate checks the constraints of the posts table that do not depend on other rows
+func validate(title string) error {
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		return fmt.Errorf("%w: title is longer than %d characters", model.ErrInvalidInput, maxTitleLength)
+	}
+	return nil
+}
+
+// withUser calls fn if the user with the given ID exists, and returns poststore.ErrUserNotFound otherwise
+// With a usermemorystore.GuardedStore, the user cannot be deleted until fn returns
+func (s *memoryStore) withUser(ctx context.Context, userID uuid.UUID, fn func() error) error {
+	if guarded, ok := s.users.(usermemorystore.GuardedStore); ok {
+		err := guarded.LockUser(userID, fn)
+		if errors.Is(err, userstore.ErrNotFound) {
+			return poststore.ErrUserNotFound
+		}
+		return err
+	}
+
+	if _, err := s.users.GetUser(ctx, userID); err != nil {
+		if errors.Is(err, userstore.ErrNotFound) {
+			return poststore.ErrUserNotFound
+		}
+		return fmt.Errorf("get user: %w", err)
+	}
+	return fn()
+}
+
+// checkNoPosts is the usermemorystore.DeleteGuard of the store, rejecting the deletion of a user who has posts
+func (s *memoryStore) checkNoPosts(_ context.Context, userID uuid.UUID) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, post := range s.posts {
+		if post.UserID == userID {
+			return fmt.Errorf("%w: user %s has posts", model.ErrInvalidReference, userID)
+		}
+	}
+	return nil
+}
+
+// restore returns a function that puts back the given version of a post, or removes the post if it did not exist
+func (s *memoryStore) restore(id uuid.UUID, post model.Post, existed bool) func() {
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if existed {
+			s.posts[id] = post
+		} else {
+			delete(s.posts, id)
+		}
+	}
+}
+
+// currentTime returns the current time with the precision and location of a PostgreSQL TIMESTAMP
+func currentTime() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
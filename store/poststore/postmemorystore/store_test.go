@@ -0,0 +1,110 @@
+package postmemorystore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/storetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usermemorystore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/memtransaction"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSuite(t *testing.T) {
+	storetest.RunStoreSuite(t, func(t *testing.T) (poststore.Store, userstore.Store) {
+		users := usermemorystore.New()
+		return New(users), users
+	})
+}
+
+func TestStore_Rollback(t *testing.T) {
+	tests := map[string]struct {
+		write func(ctx context.Context, s poststore.Store, post model.Post) error
+	}{
+		"create": {
+			write: func(ctx context.Context, s poststore.Store, post model.Post) error {
+				_, err := s.CreatePost(ctx, post.UserID, "second", "content")
+				return err
+			},
+		},
+		"update": {
+			write: func(ctx context.Context, s poststore.Store, post model.Post) error {
+				_, err := s.UpdatePost(ctx, post.ID, "new title", "new content")
+				return err
+			},
+		},
+		"delete": {
+			write: func(ctx context.Context, s poststore.Store, post model.Post) error {
+				return s.DeletePost(ctx, post.ID)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			users := usermemorystore.New()
+			s := New(users)
+			user, err := users.CreateUser(context.Background(), "alice", "alice@example.com")
+			require.NoError(t, err)
+			post, err := s.CreatePost(context.Background(), user.ID, "first", "content")
+			require.NoError(t, err)
+
+			errFn := errors.New("fn failed")
+			err = memtransaction.New().ExecTx(context.Background(), func(ctx context.Context) error {
+				require.NoError(t, tt.write(ctx, s, post))
+				return errFn
+			})
+			require.ErrorIs(t, err, errFn)
+
+			posts, err := s.ListPostsByUser(context.Background(), user.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []model.Post{post}, posts)
+		})
+	}
+}
+
+// racingUsers is a usermemorystore.GuardedStore deleting the user that CreatePost checks while the check is in progress
+type racingUsers struct {
+	usermemorystore.GuardedStore
+	deleteErr chan error
+}
+
+func (u *racingUsers) GetUser(ctx context.Context, id uuid.UUID) (model.User, error) {
+	user, err := u.GuardedStore.GetUser(ctx, id)
+	u.deleteErr <- u.DeleteUser(ctx, id)
+	return user, err
+}
+
+func (u *racingUsers) LockUser(id uuid.UUID, fn func() error) error {
+	return u.GuardedStore.LockUser(id, func() error {
+		go func() { u.deleteErr <- u.DeleteUser(context.Background(), id) }()
+		// Gives DeleteUser the time to reach its guards if the lock lets it
+		time.Sleep(10 * time.Millisecond)
+		return fn()
+	})
+}
+
+func TestStore_CreatePostWhileDeletingUser(t *testing.T) {
+	users := &racingUsers{
+		GuardedStore: usermemorystore.New().(usermemorystore.GuardedStore),
+		deleteErr:    make(chan error, 1),
+	}
+	s := New(users)
+	user, err := users.CreateUser(context.Background(), "alice", "alice@example.com")
+	require.NoError(t, err)
+
+	post, err := s.CreatePost(context.Background(), user.ID, "title", "content")
+	require.NoError(t, err)
+
+	// The user cannot be deleted while the post is created, and keeps it afterwards
+	assert.ErrorIs(t, <-users.deleteErr, model.ErrInvalidReference)
+	_, err = users.GuardedStore.GetUser(context.Background(), post.UserID)
+	assert.NoError(t, err)
+}
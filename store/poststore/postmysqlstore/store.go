@@ -0,0 +1,96 @@
+package postmysqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/mysqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txqueries"
+	"github.com/google/uuid"
+)
+
+type mysqlStore struct {
+	q *txqueries.Queries[*sql.Tx, *mysqldb.Queries]
+}
+
+// New creates a new MySQL implementation of poststore.Store
+// It runs in the *sql.Tx carried by the context, such as the one started by mysqltransaction.Manager
+func New(q *mysqldb.Queries) poststore.Store {
+	return &mysqlStore{q: txqueries.Bind(q, q.WithTx)}
+}
+
+func (s *mysqlStore) CreatePost(ctx context.Context, userID uuid.UUID, title, content string) (model.Post, error) {
+	dbParams := mysqldb.CreatePostParams{
+		ID:      uuid.New(),
+		UserID:  userID,
+		Title:   title,
+		Content: content,
+	}
+
+	q := s.q.Get(ctx)
+	if err := q.CreatePost(ctx, dbParams); err != nil {
+		return model.Post{}, toStoreError(err)
+	}
+
+	// MySQL has no RETURNING clause, so the row is read back to get the timestamps set by the database
+	dbPost, err := q.GetPost(ctx, dbParams.ID)
+	if err != nil {
+		return model.Post{}, toStoreError(err)
+	}
+
+	return toModelPost(dbPost), nil
+}
+
+func (s *mysqlStore) GetPost(ctx context.Context, id uuid.UUID) (model.Post, error) {
+	dbPost, err := s.q.Get(ctx).GetPost(ctx, id)
+	if err != nil {
+		return model.Post{}, toStoreError(err)
+	}
+
+	return toModelPost(dbPost), nil
+}
+
+func (s *mysqlStore) ListPostsByUser(ctx context.Context, userID uuid.UUID) ([]model.Post, error) {
+	dbPosts, err := s.q.Get(ctx).ListPostsByUser(ctx, userID)
+	if err != nil {
+		return nil, toStoreError(err)
+	}
+
+	return toModelPostList(dbPosts), nil
+}
+
+func (s *mysqlStore) UpdatePost(ctx context.Context, id uuid.UUID, title, content string) (model.Post, error) {
+	dbParams := mysqldb.UpdatePostParams{
+		ID:      id,
+		Title:   title,
+		Content: content,
+	}
+
+	q := s.q.Get(ctx)
+	if err := q.UpdatePost(ctx, dbParams); err != nil {
+		return model.Post{}, toStoreError(err)
+	}
+
+	// The number of affected rows cannot tell a missing post from an unchanged one,
+	// so a missing post is reported by reading the row back
+	dbPost, err := q.GetPost(ctx, id)
+	if err != nil {
+		return model.Post{}, toStoreError(err)
+	}
+
+	return toModelPost(dbPost), nil
+}
+
+func (s *mysqlStore) DeletePost(ctx context.Context, id uuid.UUID) error {
+	rows, err := s.q.Get(ctx).DeletePost(ctx, id)
+	if err != nil {
+		return toStoreError(err)
+	}
+	if rows == 0 {
+		return poststore.ErrNotFound
+	}
+
+	return nil
+}
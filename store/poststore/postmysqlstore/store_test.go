@@ -0,0 +1,39 @@
+package postmysqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/mysqltest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/mysqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/storetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usermysqlstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSuite(t *testing.T) {
+	storetest.RunStoreSuite(t, func(t *testing.T) (poststore.Store, userstore.Store) {
+		mysqltest.SkipInMemory(t, "reports too long values as unknown errors", "InvalidInput")
+		queries := mysqldb.New(mysqltest.NewDB(t))
+		return New(queries), usermysqlstore.New(queries)
+	})
+}
+
+func TestDeleteUserWithPosts(t *testing.T) {
+	queries := mysqldb.New(mysqltest.NewDB(t))
+	users := usermysqlstore.New(queries)
+	ctx := context.Background()
+
+	user, err := users.CreateUser(ctx, "alice", "alice@example.com")
+	require.NoError(t, err)
+	_, err = New(queries).CreatePost(ctx, user.ID, "title", "content")
+	require.NoError(t, err)
+
+	// Like PostgreSQL, MySQL rejects deleting a user who still has posts
+	err = users.DeleteUser(ctx, user.ID)
+	assert.ErrorIs(t, err, model.ErrInvalidReference)
+}
@@ -1,9 +1,15 @@
 package postpgstore
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
 	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/db"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/pgerr"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -40,3 +46,19 @@ func fromPgTypeUUID(id pgtype.UUID) uuid.UUID {
 	}
 	return id.Bytes
 }
+
+// Converts errors returned by the queries to the errors documented by poststore.Store
+// The original error stays in the chain
+func toStoreError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %w", poststore.ErrNotFound, err)
+	}
+
+	// user_id is the only foreign key of the posts table
+	translated := pgerr.Translate(err)
+	if errors.Is(translated, model.ErrInvalidReference) {
+		return fmt.Errorf("%w: %w", poststore.ErrUserNotFound, err)
+	}
+
+	return translated
+}
@@ -6,16 +6,18 @@ import (
 	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
 	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/db"
 	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txqueries"
 	"github.com/google/uuid"
 )
 
 type pgStore struct {
-	q *db.Queries
+	q *txqueries.Queries[db.DBTX, *db.Queries]
 }
 
 // New creates a new PostgreSQL implementation of poststore.Store
+// It runs in the pgx.Tx carried by the context, such as the one started by pgxtransaction.Manager
 func New(q *db.Queries) poststore.Store {
-	return &pgStore{q: q}
+	return &pgStore{q: txqueries.Bind(q, q.WithTx)}
 }
 
 func (s *pgStore) CreatePost(ctx context.Context, userID uuid.UUID, title, content string) (model.Post, error) {
@@ -25,18 +27,18 @@ func (s *pgStore) CreatePost(ctx context.Context, userID uuid.UUID, title, conte
 		Content: content,
 	}
 
-	dbPost, err := s.q.CreatePost(ctx, dbParams)
+	dbPost, err := s.q.Get(ctx).CreatePost(ctx, dbParams)
 	if err != nil {
-		return model.Post{}, err
+		return model.Post{}, toStoreError(err)
 	}
 
 	return toModelPost(dbPost), nil
 }
 
 func (s *pgStore) GetPost(ctx context.Context, id uuid.UUID) (model.Post, error) {
-	dbPost, err := s.q.GetPost(ctx, id)
+	dbPost, err := s.q.Get(ctx).GetPost(ctx, id)
 	if err != nil {
-		return model.Post{}, err
+		return model.Post{}, toStoreError(err)
 	}
 
 	return toModelPost(dbPost), nil
@@ -44,9 +46,9 @@ func (s *pgStore) GetPost(ctx context.Context, id uuid.UUID) (model.Post, error)
 
 func (s *pgStore) ListPostsByUser(ctx context.Context, userID uuid.UUID) ([]model.Post, error) {
 	pgUserID := toPgTypeUUID(userID)
-	dbPosts, err := s.q.ListPostsByUser(ctx, pgUserID)
+	dbPosts, err := s.q.Get(ctx).ListPostsByUser(ctx, pgUserID)
 	if err != nil {
-		return nil, err
+		return nil, toStoreError(err)
 	}
 
 	return toModelPostList(dbPosts), nil
@@ -59,14 +61,22 @@ func (s *pgStore) UpdatePost(ctx context.Context, id uuid.UUID, title, content s
 		Content: content,
 	}
 
-	dbPost, err := s.q.UpdatePost(ctx, dbParams)
+	dbPost, err := s.q.Get(ctx).UpdatePost(ctx, dbParams)
 	if err != nil {
-		return model.Post{}, err
+		return model.Post{}, toStoreError(err)
 	}
 
 	return toModelPost(dbPost), nil
 }
 
 func (s *pgStore) DeletePost(ctx context.Context, id uuid.UUID) error {
-	return s.q.DeletePost(ctx, id)
+	rows, err := s.q.Get(ctx).DeletePost(ctx, id)
+	if err != nil {
+		return toStoreError(err)
+	}
+	if rows == 0 {
+		return poststore.ErrNotFound
+	}
+
+	return nil
 }
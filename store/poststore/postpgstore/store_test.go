@@ -0,0 +1,19 @@
+package postpgstore
+
+import (
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/pgtest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/db"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/storetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/userpgstore"
+)
+
+func TestStoreSuite(t *testing.T) {
+	storetest.RunStoreSuite(t, func(t *testing.T) (poststore.Store, userstore.Store) {
+		queries := db.New(pgtest.NewPool(t))
+		return New(queries), userpgstore.New(queries)
+	})
+}
@@ -0,0 +1,83 @@
+package postsqlitestore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqlitedb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txqueries"
+	"github.com/google/uuid"
+)
+
+type sqliteStore struct {
+	q *txqueries.Queries[*sql.Tx, *sqlitedb.Queries]
+}
+
+// New creates a new SQLite implementation of poststore.Store
+// It runs in the *sql.Tx carried by the context, such as the one started by sqlitetransaction.Manager
+func New(q *sqlitedb.Queries) poststore.Store {
+	return &sqliteStore{q: txqueries.Bind(q, q.WithTx)}
+}
+
+func (s *sqliteStore) CreatePost(ctx context.Context, userID uuid.UUID, title, content string) (model.Post, error) {
+	dbParams := sqlitedb.CreatePostParams{
+		ID:      uuid.New(),
+		UserID:  userID,
+		Title:   title,
+		Content: content,
+	}
+
+	dbPost, err := s.q.Get(ctx).CreatePost(ctx, dbParams)
+	if err != nil {
+		return model.Post{}, toStoreError(err)
+	}
+
+	return toModelPost(dbPost), nil
+}
+
+func (s *sqliteStore) GetPost(ctx context.Context, id uuid.UUID) (model.Post, error) {
+	dbPost, err := s.q.Get(ctx).GetPost(ctx, id)
+	if err != nil {
+		return model.Post{}, toStoreError(err)
+	}
+
+	return toModelPost(dbPost), nil
+}
+
+func (s *sqliteStore) ListPostsByUser(ctx context.Context, userID uuid.UUID) ([]model.Post, error) {
+	dbPosts, err := s.q.Get(ctx).ListPostsByUser(ctx, userID)
+	if err != nil {
+		return nil, toStoreError(err)
+	}
+
+	return toModelPostList(dbPosts), nil
+}
+
+func (s *sqliteStore) UpdatePost(ctx context.Context, id uuid.UUID, title, content string) (model.Post, error) {
+	dbParams := sqlitedb.UpdatePostParams{
+		ID:      id,
+		Title:   title,
+		Content: content,
+	}
+
+	dbPost, err := s.q.Get(ctx).UpdatePost(ctx, dbParams)
+	if err != nil {
+		return model.Post{}, toStoreError(err)
+	}
+
+	return toModelPost(dbPost), nil
+}
+
+func (s *sqliteStore) DeletePost(ctx context.Context, id uuid.UUID) error {
+	rows, err := s.q.Get(ctx).DeletePost(ctx, id)
+	if err != nil {
+		return toStoreError(err)
+	}
+	if rows == 0 {
+		return poststore.ErrNotFound
+	}
+
+	return nil
+}
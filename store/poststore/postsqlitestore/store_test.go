@@ -0,0 +1,38 @@
+package postsqlitestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/sqlitetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqlitedb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/storetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usersqlitestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSuite(t *testing.T) {
+	storetest.RunStoreSuite(t, func(t *testing.T) (poststore.Store, userstore.Store) {
+		queries := sqlitedb.New(sqlitetest.NewDB(t))
+		return New(queries), usersqlitestore.New(queries)
+	})
+}
+
+func TestDeleteUserWithPosts(t *testing.T) {
+	queries := sqlitedb.New(sqlitetest.NewDB(t))
+	users := usersqlitestore.New(queries)
+	ctx := context.Background()
+
+	user, err := users.CreateUser(ctx, "alice", "alice@example.com")
+	require.NoError(t, err)
+	_, err = New(queries).CreatePost(ctx, user.ID, "title", "content")
+	require.NoError(t, err)
+
+	// Like PostgreSQL, SQLite rejects deleting a user who still has posts
+	err = users.DeleteUser(ctx, user.ID)
+	assert.ErrorIs(t, err, model.ErrInvalidReference)
+}
@@ -0,0 +1,49 @@
+package postsqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/pgerr"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+)
+
+// Converts from sqldb.Post to model.Post
+func toModelPost(dbPost sqldb.Post) model.Post {
+	return model.Post{
+		ID:        dbPost.ID,
+		UserID:    dbPost.UserID,
+		Title:     dbPost.Title,
+		Content:   dbPost.Content,
+		CreatedAt: dbPost.CreatedAt,
+		UpdatedAt: dbPost.UpdatedAt,
+	}
+}
+
+// Converts from sqldb.Post slice to model.Post slice
+func toModelPostList(dbPosts []sqldb.Post) []model.Post {
+	posts := make([]model.Post, len(dbPosts))
+	for i, dbPost := range dbPosts {
+		posts[i] = toModelPost(dbPost)
+	}
+	return posts
+}
+
+// Converts errors returned by the queries to the errors documented by poststore.Store
+// The original error stays in the chain
+func toStoreError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", poststore.ErrNotFound, err)
+	}
+
+	// user_id is the only foreign key of the posts table
+	translated := pgerr.Translate(err)
+	if errors.Is(translated, model.ErrInvalidReference) {
+		return fmt.Errorf("%w: %w", poststore.ErrUserNotFound, err)
+	}
+
+	return translated
+}
@@ -0,0 +1,82 @@
+package postsqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txqueries"
+	"github.com/google/uuid"
+)
+
+type sqlStore struct {
+	q *txqueries.Queries[*sql.Tx, *sqldb.Queries]
+}
+
+// New creates a new database/sql implementation of poststore.Store for PostgreSQL
+// It runs in the *sql.Tx carried by the context, such as the one started by sqltransaction.Manager
+func New(q *sqldb.Queries) poststore.Store {
+	return &sqlStore{q: txqueries.Bind(q, q.WithTx)}
+}
+
+func (s *sqlStore) CreatePost(ctx context.Context, userID uuid.UUID, title, content string) (model.Post, error) {
+	dbParams := sqldb.CreatePostParams{
+		UserID:  userID,
+		Title:   title,
+		Content: content,
+	}
+
+	dbPost, err := s.q.Get(ctx).CreatePost(ctx, dbParams)
+	if err != nil {
+		return model.Post{}, toStoreError(err)
+	}
+
+	return toModelPost(dbPost), nil
+}
+
+func (s *sqlStore) GetPost(ctx context.Context, id uuid.UUID) (model.Post, error) {
+	dbPost, err := s.q.Get(ctx).GetPost(ctx, id)
+	if err != nil {
+		return model.Post{}, toStoreError(err)
+	}
+
+	return toModelPost(dbPost), nil
+}
+
+func (s *sqlStore) ListPostsByUser(ctx context.Context, userID uuid.UUID) ([]model.Post, error) {
+	dbPosts, err := s.q.Get(ctx).ListPostsByUser(ctx, userID)
+	if err != nil {
+		return nil, toStoreError(err)
+	}
+
+	return toModelPostList(dbPosts), nil
+}
+
+func (s *sqlStore) UpdatePost(ctx context.Context, id uuid.UUID, title, content string) (model.Post, error) {
+	dbParams := sqldb.UpdatePostParams{
+		ID:      id,
+		Title:   title,
+		Content: content,
+	}
+
+	dbPost, err := s.q.Get(ctx).UpdatePost(ctx, dbParams)
+	if err != nil {
+		return model.Post{}, toStoreError(err)
+	}
+
+	return toModelPost(dbPost), nil
+}
+
+func (s *sqlStore) DeletePost(ctx context.Context, id uuid.UUID) error {
+	rows, err := s.q.Get(ctx).DeletePost(ctx, id)
+	if err != nil {
+		return toStoreError(err)
+	}
+	if rows == 0 {
+		return poststore.ErrNotFound
+	}
+
+	return nil
+}
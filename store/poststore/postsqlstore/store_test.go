@@ -0,0 +1,19 @@
+package postsqlstore
+
+import (
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/pgtest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/storetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usersqlstore"
+)
+
+func TestStoreSuite(t *testing.T) {
+	storetest.RunStoreSuite(t, func(t *testing.T) (poststore.Store, userstore.Store) {
+		queries := sqldb.New(pgtest.NewDB(t))
+		return New(queries), usersqlstore.New(queries)
+	})
+}
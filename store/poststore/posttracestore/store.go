@@ -0,0 +1,82 @@
+// Package posttracestore traces the calls to a poststore.Store with OpenTelemetry
+package posttracestore
+
+import (
+	"context"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans created by this package
+const ScopeName = "github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/posttracestore"
+
+type traceStore struct {
+	next   poststore.Store
+	tracer trace.Tracer
+}
+
+// New creates a poststore.Store running each call of next in a span named after the method, e.g. "poststore.GetPost"
+// The spans use the tracer provider tp, or the global one if tp is nil
+func New(next poststore.Store, tp trace.TracerProvider) poststore.Store {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &traceStore{
+		next:   next,
+		tracer: tp.Tracer(ScopeName),
+	}
+}
+
+func (s *traceStore) CreatePost(ctx context.Context, userID uuid.UUID, title, content string) (post model.Post, err error) {
+	ctx, span := s.start(ctx, "poststore.CreatePost")
+	defer func() { end(span, err) }()
+
+	return s.next.CreatePost(ctx, userID, title, content)
+}
+
+func (s *traceStore) GetPost(ctx context.Context, id uuid.UUID) (post model.Post, err error) {
+	ctx, span := s.start(ctx, "poststore.GetPost")
+	defer func() { end(span, err) }()
+
+	return s.next.GetPost(ctx, id)
+}
+
+func (s *traceStore) ListPostsByUser(ctx context.Context, userID uuid.UUID) (posts []model.Post, err error) {
+	ctx, span := s.start(ctx, "poststore.ListPostsByUser")
+	defer func() { end(span, err) }()
+
+	return s.next.ListPostsByUser(ctx, userID)
+}
+
+func (s *traceStore) UpdatePost(ctx context.Context, id uuid.UUID, title, content string) (post model.Post, err error) {
+	ctx, span := s.start(ctx, "poststore.UpdatePost")
+	defer func() { end(span, err) }()
+
+	return s.next.UpdatePost(ctx, id, title, content)
+}
+
+func (s *traceStore) DeletePost(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := s.start(ctx, "poststore.DeletePost")
+	defer func() { end(span, err) }()
+
+	return s.next.DeletePost(ctx, id)
+}
+
+// start starts the span of a call
+func (s *traceStore) start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal))
+}
+
+// end records the error of a call, if any, and ends its span
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
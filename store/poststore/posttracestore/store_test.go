@@ -0,0 +1,61 @@
+package posttracestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/postmemorystore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore/storetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usermemorystore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStoreSuite(t *testing.T) {
+	storetest.RunStoreSuite(t, func(t *testing.T) (poststore.Store, userstore.Store) {
+		users := usermemorystore.New()
+		return New(postmemorystore.New(users), nil), users
+	})
+}
+
+func TestStore_Spans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	users := usermemorystore.New()
+	s := New(postmemorystore.New(users), tp)
+	ctx := context.Background()
+
+	user, err := users.CreateUser(ctx, "alice", "alice@example.com")
+	require.NoError(t, err)
+	post, err := s.CreatePost(ctx, user.ID, "title", "content")
+	require.NoError(t, err)
+	_, err = s.GetPost(ctx, uuid.New())
+	require.ErrorIs(t, err, poststore.ErrNotFound)
+	_, err = s.ListPostsByUser(ctx, user.ID)
+	require.NoError(t, err)
+	_, err = s.UpdatePost(ctx, post.ID, "new title", "new content")
+	require.NoError(t, err)
+	require.NoError(t, s.DeletePost(ctx, post.ID))
+
+	var names []string
+	var statuses []codes.Code
+	for _, span := range exporter.GetSpans() {
+		names = append(names, span.Name)
+		statuses = append(statuses, span.Status.Code)
+	}
+	assert.Equal(t, []string{
+		"poststore.CreatePost",
+		"poststore.GetPost",
+		"poststore.ListPostsByUser",
+		"poststore.UpdatePost",
+		"poststore.DeletePost",
+	}, names)
+	assert.Equal(t, []codes.Code{codes.Unset, codes.Error, codes.Unset, codes.Unset, codes.Unset}, statuses)
+}
@@ -4,25 +4,42 @@ package poststore
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
 	"github.com/google/uuid"
 )
 
+// Errors returned by Store implementations
+// They wrap the domain errors of the model package, which can be matched with errors.Is as well
+var (
+	// ErrNotFound is returned when no post has the requested ID
+	ErrNotFound = fmt.Errorf("post %w", model.ErrNotFound)
+
+	// ErrUserNotFound is returned when a post is created for a user that does not exist
+	ErrUserNotFound = fmt.Errorf("post user: %w", model.ErrInvalidReference)
+)
+
 // Store defines the interface for post store operations
+// Every implementation must pass the suite in the storetest package
 type Store interface {
 	// CreatePost creates a new post
+	// It returns ErrUserNotFound if there is no user with the given ID,
+	// or model.ErrInvalidInput if the title is longer than 255 characters
 	CreatePost(ctx context.Context, userID uuid.UUID, title, content string) (model.Post, error)
 
 	// GetPost retrieves a post by ID
+	// It returns ErrNotFound if there is no such post
 	GetPost(ctx context.Context, id uuid.UUID) (model.Post, error)
 
-	// ListPostsByUser lists all posts by a user
+	// ListPostsByUser lists all posts by a user, newest first
 	ListPostsByUser(ctx context.Context, userID uuid.UUID) ([]model.Post, error)
 
-	// UpdatePost updates a post
+	// UpdatePost updates a post and refreshes its UpdatedAt timestamp
+	// It returns ErrNotFound if there is no such post, or model.ErrInvalidInput if the title is longer than 255 characters
 	UpdatePost(ctx context.Context, id uuid.UUID, title, content string) (model.Post, error)
 
 	// DeletePost deletes a post
+	// It returns ErrNotFound if there is no such post
 	DeletePost(ctx context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,230 @@
+// Package storetest provides a conformance test suite for poststore.Store implementations
+package storetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/poststore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns an empty Store along with the user store its posts refer to
+// It is called once per test case and should register its cleanup with t.Cleanup
+type Factory func(t *testing.T) (poststore.Store, userstore.Store)
+
+// clockSkew is the tolerated difference between the clock of the test and the one of the store
+const clockSkew = time.Minute
+
+// RunStoreSuite checks that the Store returned by factory fulfills the poststore.Store contract
+func RunStoreSuite(t *testing.T, factory Factory) {
+	t.Run("CreatePost", func(t *testing.T) { testCreatePost(t, factory) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, factory) })
+	t.Run("ListPostsByUser", func(t *testing.T) { testListPostsByUser(t, factory) })
+	t.Run("UpdatePost", func(t *testing.T) { testUpdatePost(t, factory) })
+	t.Run("DeletePost", func(t *testing.T) { testDeletePost(t, factory) })
+	t.Run("ForeignKey", func(t *testing.T) { testForeignKey(t, factory) })
+	t.Run("InvalidInput", func(t *testing.T) { testInvalidInput(t, factory) })
+}
+
+// createUser creates a user the posts can refer to
+func createUser(t *testing.T, users userstore.Store, name string) model.User {
+	t.Helper()
+
+	user, err := users.CreateUser(context.Background(), name, name+"@example.com")
+	require.NoError(t, err)
+	return user
+}
+
+func testCreatePost(t *testing.T, factory Factory) {
+	s, users := factory(t)
+	ctx := context.Background()
+	user := createUser(t, users, "alice")
+
+	before := time.Now()
+	post, err := s.CreatePost(ctx, user.ID, "title", "content")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, uuid.Nil, post.ID)
+	assert.Equal(t, user.ID, post.UserID)
+	assert.Equal(t, "title", post.Title)
+	assert.Equal(t, "content", post.Content)
+	assert.WithinDuration(t, before, post.CreatedAt, clockSkew)
+	assert.Equal(t, post.CreatedAt, post.UpdatedAt)
+
+	got, err := s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, post, got)
+}
+
+func testNotFound(t *testing.T, factory Factory) {
+	s, _ := factory(t)
+	ctx := context.Background()
+	id := uuid.New()
+
+	_, err := s.GetPost(ctx, id)
+	assert.ErrorIs(t, err, poststore.ErrNotFound, "GetPost")
+	assert.ErrorIs(t, err, model.ErrNotFound, "GetPost")
+
+	_, err = s.UpdatePost(ctx, id, "title", "content")
+	assert.ErrorIs(t, err, poststore.ErrNotFound, "UpdatePost")
+
+	err = s.DeletePost(ctx, id)
+	assert.ErrorIs(t, err, poststore.ErrNotFound, "DeletePost")
+}
+
+func testListPostsByUser(t *testing.T, factory Factory) {
+	t.Run("empty", func(t *testing.T) {
+		s, users := factory(t)
+		user := createUser(t, users, "alice")
+
+		posts, err := s.ListPostsByUser(context.Background(), user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, posts)
+	})
+
+	t.Run("only the posts of the user, newest first", func(t *testing.T) {
+		s, users := factory(t)
+		ctx := context.Background()
+		alice := createUser(t, users, "alice")
+		bob := createUser(t, users, "bob")
+
+		for _, title := range []string{"first", "second", "third"} {
+			_, err := s.CreatePost(ctx, alice.ID, title, "content")
+			require.NoError(t, err)
+			_, err = s.CreatePost(ctx, bob.ID, "bob "+title, "content")
+			require.NoError(t, err)
+
+			// Keep the creation timestamps apart so that the order is well defined
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		posts, err := s.ListPostsByUser(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"third", "second", "first"}, titles(posts))
+	})
+}
+
+func testUpdatePost(t *testing.T, factory Factory) {
+	s, users := factory(t)
+	ctx := context.Background()
+	user := createUser(t, users, "alice")
+
+	post, err := s.CreatePost(ctx, user.ID, "title", "content")
+	require.NoError(t, err)
+
+	// Make sure the update happens at a later timestamp than the creation
+	time.Sleep(2 * time.Millisecond)
+
+	updated, err := s.UpdatePost(ctx, post.ID, "new title", "new content")
+	require.NoError(t, err)
+
+	assert.Equal(t, post.ID, updated.ID)
+	assert.Equal(t, user.ID, updated.UserID)
+	assert.Equal(t, "new title", updated.Title)
+	assert.Equal(t, "new content", updated.Content)
+	assert.Equal(t, post.CreatedAt, updated.CreatedAt)
+	assert.True(t, updated.UpdatedAt.After(post.UpdatedAt), "UpdatedAt should move forward")
+
+	got, err := s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated, got)
+}
+
+func testDeletePost(t *testing.T, factory Factory) {
+	s, users := factory(t)
+	ctx := context.Background()
+	user := createUser(t, users, "alice")
+
+	post, err := s.CreatePost(ctx, user.ID, "title", "content")
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeletePost(ctx, post.ID))
+
+	_, err = s.GetPost(ctx, post.ID)
+	assert.ErrorIs(t, err, poststore.ErrNotFound)
+
+	err = s.DeletePost(ctx, post.ID)
+	assert.ErrorIs(t, err, poststore.ErrNotFound)
+}
+
+func testForeignKey(t *testing.T, factory Factory) {
+	t.Run("create for an unknown user", func(t *testing.T) {
+		s, _ := factory(t)
+		ctx := context.Background()
+		userID := uuid.New()
+
+		_, err := s.CreatePost(ctx, userID, "title", "content")
+		assert.ErrorIs(t, err, poststore.ErrUserNotFound)
+		assert.ErrorIs(t, err, model.ErrInvalidReference)
+
+		posts, err := s.ListPostsByUser(ctx, userID)
+		require.NoError(t, err)
+		assert.Empty(t, posts)
+	})
+
+	t.Run("create for a deleted user", func(t *testing.T) {
+		s, users := factory(t)
+		ctx := context.Background()
+		user := createUser(t, users, "alice")
+		require.NoError(t, users.DeleteUser(ctx, user.ID))
+
+		_, err := s.CreatePost(ctx, user.ID, "title", "content")
+		assert.ErrorIs(t, err, poststore.ErrUserNotFound)
+	})
+
+	t.Run("delete a user that has posts", func(t *testing.T) {
+		s, users := factory(t)
+		ctx := context.Background()
+		user := createUser(t, users, "alice")
+		post, err := s.CreatePost(ctx, user.ID, "title", "content")
+		require.NoError(t, err)
+
+		err = users.DeleteUser(ctx, user.ID)
+		assert.ErrorIs(t, err, model.ErrInvalidReference)
+
+		_, err = users.GetUser(ctx, user.ID)
+		assert.NoError(t, err)
+		_, err = s.GetPost(ctx, post.ID)
+		assert.NoError(t, err)
+
+		// Once its posts are deleted, the user can be deleted
+		require.NoError(t, s.DeletePost(ctx, post.ID))
+		assert.NoError(t, users.DeleteUser(ctx, user.ID))
+	})
+}
+
+func testInvalidInput(t *testing.T, factory Factory) {
+	s, users := factory(t)
+	ctx := context.Background()
+	user := createUser(t, users, "alice")
+	title := strings.Repeat("a", 256)
+
+	_, err := s.CreatePost(ctx, user.ID, title, "content")
+	assert.ErrorIs(t, err, model.ErrInvalidInput, "CreatePost")
+
+	post, err := s.CreatePost(ctx, user.ID, "title", "content")
+	require.NoError(t, err)
+
+	_, err = s.UpdatePost(ctx, post.ID, title, "content")
+	assert.ErrorIs(t, err, model.ErrInvalidInput, "UpdatePost")
+
+	got, err := s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, post, got)
+}
+
+// titles returns the titles of posts, in order
+func titles(posts []model.Post) []string {
+	result := make([]string, len(posts))
+	for i, post := range posts {
+		result[i] = post.Title
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+// Package sqliteerr translates the errors returned by SQLite into the domain errors of the model package
+package sqliteerr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// uniqueMessage extracts the column from the message of a unique violation, e.g. "UNIQUE constraint failed: users.email"
+var uniqueMessage = regexp.MustCompile(`UNIQUE constraint failed: \w+\.(\w+)`)
+
+// Translate converts err into a domain error, keeping err in the chain:
+//   - sql.ErrNoRows becomes model.ErrNotFound
+//   - unique and primary key violations become a *model.ErrConflict on the violated column
+//   - foreign key violations become model.ErrInvalidReference
+//   - not-null and check violations become model.ErrInvalidInput
+//
+// Other errors are returned unchanged
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", model.ErrNotFound, err)
+	}
+
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return &model.ErrConflict{Field: conflictField(sqliteErr), Err: err}
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return fmt.Errorf("%w: %w", model.ErrInvalidReference, err)
+	case sqlite3.SQLITE_CONSTRAINT_NOTNULL, sqlite3.SQLITE_CONSTRAINT_CHECK:
+		return fmt.Errorf("%w: %w", model.ErrInvalidInput, err)
+	default:
+		return err
+	}
+}
+
+// conflictField returns the column of a unique violation, or an empty string when it cannot be told
+func conflictField(err *sqlite.Error) string {
+	if m := uniqueMessage.FindStringSubmatch(err.Error()); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package sqliteerr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestTranslate(t *testing.T) {
+	errOther := errors.New("connection refused")
+
+	tests := map[string]struct {
+		query       string
+		err         error
+		expectedErr error
+		unchanged   bool
+	}{
+		"no rows": {
+			err:         sql.ErrNoRows,
+			expectedErr: model.ErrNotFound,
+		},
+		"unique violation": {
+			query:       "INSERT INTO users (id, email) VALUES ('2', 'alice@example.com')",
+			expectedErr: &model.ErrConflict{Field: "email"},
+		},
+		"primary key violation": {
+			query:       "INSERT INTO users (id, email) VALUES ('1', 'bob@example.com')",
+			expectedErr: &model.ErrConflict{Field: "id"},
+		},
+		"foreign key violation": {
+			query:       "INSERT INTO posts (id, user_id) VALUES ('1', 'unknown')",
+			expectedErr: model.ErrInvalidReference,
+		},
+		"not-null violation": {
+			query:       "INSERT INTO users (id) VALUES ('2')",
+			expectedErr: model.ErrInvalidInput,
+		},
+		"check violation": {
+			query:       "INSERT INTO users (id, email) VALUES ('2', '')",
+			expectedErr: model.ErrInvalidInput,
+		},
+		"other sqlite error": {
+			query:     "SELECT * FROM unknown",
+			unchanged: true,
+		},
+		"other error": {
+			err:       errOther,
+			unchanged: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			inputErr := tt.err
+			if tt.query != "" {
+				_, inputErr = newDB(t).ExecContext(context.Background(), tt.query)
+				require.Error(t, inputErr)
+			}
+
+			err := Translate(inputErr)
+
+			if tt.unchanged {
+				assert.Equal(t, inputErr, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedErr)
+			// The original error stays in the chain
+			assert.ErrorIs(t, err, inputErr)
+		})
+	}
+
+	assert.NoError(t, Translate(nil))
+}
+
+// newDB returns an in-memory database holding a user with ID 1
+func newDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(1)")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.ExecContext(context.Background(), `
+		CREATE TABLE users (id TEXT PRIMARY KEY, email TEXT NOT NULL UNIQUE CHECK (email <> ''));
+		CREATE TABLE posts (id TEXT PRIMARY KEY, user_id TEXT NOT NULL REFERENCES users(id));
+		INSERT INTO users (id, email) VALUES ('1', 'alice@example.com');
+	`)
+	require.NoError(t, err)
+	return db
+}
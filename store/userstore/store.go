@@ -4,25 +4,45 @@ package userstore
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
 	"github.com/google/uuid"
 )
 
+// Errors returned by Store implementations
+// They wrap the domain errors of the model package, which can be matched with errors.Is as well
+var (
+	// ErrNotFound is returned when no user has the requested ID
+	ErrNotFound = fmt.Errorf("user %w", model.ErrNotFound)
+
+	// ErrDuplicateEmail is returned when the email is already used by another user
+	// Any *model.ErrConflict on the email field matches it with errors.Is
+	ErrDuplicateEmail = &model.ErrConflict{Field: "email"}
+)
+
 // Store defines the interface for user store operations
+// Every implementation must pass the suite in the storetest package
 type Store interface {
 	// CreateUser creates a new user
+	// It returns ErrDuplicateEmail if another user has the same email,
+	// or model.ErrInvalidInput if the name is longer than 100 characters or the email longer than 255
 	CreateUser(ctx context.Context, name, email string) (model.User, error)
 
 	// GetUser retrieves a user by ID
+	// It returns ErrNotFound if there is no such user
 	GetUser(ctx context.Context, id uuid.UUID) (model.User, error)
 
-	// ListUsers lists all users
+	// ListUsers lists all users, ordered by name
 	ListUsers(ctx context.Context) ([]model.User, error)
 
-	// UpdateUser updates a user
+	// UpdateUser updates a user and refreshes its UpdatedAt timestamp
+	// It returns ErrNotFound if there is no such user, ErrDuplicateEmail if another user has the same email,
+	// or model.ErrInvalidInput if the name is longer than 100 characters or the email longer than 255
 	UpdateUser(ctx context.Context, id uuid.UUID, name, email string) (model.User, error)
 
 	// DeleteUser deletes a user
+	// It returns ErrNotFound if there is no such user,
+	// or model.ErrInvalidReference if the user still has posts
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,230 @@
+// Package storetest provides a conformance test suite for userstore.Store implementations
+package storetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns an empty Store
+// It is called once per test case and should register its cleanup with t.Cleanup
+type Factory func(t *testing.T) userstore.Store
+
+// clockSkew is the tolerated difference between the clock of the test and the one of the store
+const clockSkew = time.Minute
+
+// RunStoreSuite checks that the Store returned by factory fulfills the userstore.Store contract
+func RunStoreSuite(t *testing.T, factory Factory) {
+	t.Run("CreateUser", func(t *testing.T) { testCreateUser(t, factory) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, factory) })
+	t.Run("ListUsers", func(t *testing.T) { testListUsers(t, factory) })
+	t.Run("UpdateUser", func(t *testing.T) { testUpdateUser(t, factory) })
+	t.Run("DeleteUser", func(t *testing.T) { testDeleteUser(t, factory) })
+	t.Run("UniqueEmail", func(t *testing.T) { testUniqueEmail(t, factory) })
+	t.Run("InvalidInput", func(t *testing.T) { testInvalidInput(t, factory) })
+}
+
+func testCreateUser(t *testing.T, factory Factory) {
+	s := factory(t)
+	ctx := context.Background()
+
+	before := time.Now()
+	user, err := s.CreateUser(ctx, "alice", "alice@example.com")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, uuid.Nil, user.ID)
+	assert.Equal(t, "alice", user.Name)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.WithinDuration(t, before, user.CreatedAt, clockSkew)
+	assert.Equal(t, user.CreatedAt, user.UpdatedAt)
+
+	got, err := s.GetUser(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+}
+
+func testNotFound(t *testing.T, factory Factory) {
+	s := factory(t)
+	ctx := context.Background()
+	id := uuid.New()
+
+	_, err := s.GetUser(ctx, id)
+	assert.ErrorIs(t, err, userstore.ErrNotFound, "GetUser")
+	assert.ErrorIs(t, err, model.ErrNotFound, "GetUser")
+
+	_, err = s.UpdateUser(ctx, id, "alice", "alice@example.com")
+	assert.ErrorIs(t, err, userstore.ErrNotFound, "UpdateUser")
+
+	err = s.DeleteUser(ctx, id)
+	assert.ErrorIs(t, err, userstore.ErrNotFound, "DeleteUser")
+}
+
+func testListUsers(t *testing.T, factory Factory) {
+	t.Run("empty", func(t *testing.T) {
+		s := factory(t)
+
+		users, err := s.ListUsers(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("ordered by name", func(t *testing.T) {
+		s := factory(t)
+		ctx := context.Background()
+
+		for _, name := range []string{"carol", "alice", "bob"} {
+			_, err := s.CreateUser(ctx, name, name+"@example.com")
+			require.NoError(t, err)
+		}
+
+		users, err := s.ListUsers(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice", "bob", "carol"}, names(users))
+	})
+}
+
+func testUpdateUser(t *testing.T, factory Factory) {
+	s := factory(t)
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, "alice", "alice@example.com")
+	require.NoError(t, err)
+
+	// Make sure the update happens at a later timestamp than the creation
+	time.Sleep(2 * time.Millisecond)
+
+	updated, err := s.UpdateUser(ctx, user.ID, "alicia", "alicia@example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, user.ID, updated.ID)
+	assert.Equal(t, "alicia", updated.Name)
+	assert.Equal(t, "alicia@example.com", updated.Email)
+	assert.Equal(t, user.CreatedAt, updated.CreatedAt)
+	assert.True(t, updated.UpdatedAt.After(user.UpdatedAt), "UpdatedAt should move forward")
+
+	got, err := s.GetUser(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated, got)
+}
+
+func testDeleteUser(t *testing.T, factory Factory) {
+	s := factory(t)
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, "alice", "alice@example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteUser(ctx, user.ID))
+
+	_, err = s.GetUser(ctx, user.ID)
+	assert.ErrorIs(t, err, userstore.ErrNotFound)
+
+	err = s.DeleteUser(ctx, user.ID)
+	assert.ErrorIs(t, err, userstore.ErrNotFound)
+}
+
+func testUniqueEmail(t *testing.T, factory Factory) {
+	t.Run("create with a used email", func(t *testing.T) {
+		s := factory(t)
+		ctx := context.Background()
+
+		_, err := s.CreateUser(ctx, "alice", "alice@example.com")
+		require.NoError(t, err)
+
+		_, err = s.CreateUser(ctx, "alice 2", "alice@example.com")
+		assert.ErrorIs(t, err, userstore.ErrDuplicateEmail)
+
+		var conflict *model.ErrConflict
+		if assert.ErrorAs(t, err, &conflict) {
+			assert.Equal(t, "email", conflict.Field)
+		}
+
+		users, err := s.ListUsers(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice"}, names(users))
+	})
+
+	t.Run("update to a used email", func(t *testing.T) {
+		s := factory(t)
+		ctx := context.Background()
+
+		_, err := s.CreateUser(ctx, "alice", "alice@example.com")
+		require.NoError(t, err)
+		bob, err := s.CreateUser(ctx, "bob", "bob@example.com")
+		require.NoError(t, err)
+
+		_, err = s.UpdateUser(ctx, bob.ID, "bob", "alice@example.com")
+		assert.ErrorIs(t, err, userstore.ErrDuplicateEmail)
+
+		got, err := s.GetUser(ctx, bob.ID)
+		require.NoError(t, err)
+		assert.Equal(t, bob, got)
+	})
+
+	t.Run("update keeping the same email", func(t *testing.T) {
+		s := factory(t)
+		ctx := context.Background()
+
+		alice, err := s.CreateUser(ctx, "alice", "alice@example.com")
+		require.NoError(t, err)
+
+		_, err = s.UpdateUser(ctx, alice.ID, "alicia", "alice@example.com")
+		assert.NoError(t, err)
+	})
+}
+
+func testInvalidInput(t *testing.T, factory Factory) {
+	tests := map[string]struct {
+		name  string
+		email string
+	}{
+		"name longer than 100 characters": {
+			name:  strings.Repeat("a", 101),
+			email: "alice@example.com",
+		},
+		"email longer than 255 characters": {
+			name:  "alice",
+			email: strings.Repeat("a", 244) + "@example.com",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := factory(t)
+			ctx := context.Background()
+
+			_, err := s.CreateUser(ctx, tt.name, tt.email)
+			assert.ErrorIs(t, err, model.ErrInvalidInput, "CreateUser")
+
+			alice, err := s.CreateUser(ctx, "alice", "alice@example.com")
+			require.NoError(t, err)
+
+			_, err = s.UpdateUser(ctx, alice.ID, tt.name, tt.email)
+			assert.ErrorIs(t, err, model.ErrInvalidInput, "UpdateUser")
+		})
+	}
+
+	t.Run("multibyte characters count once", func(t *testing.T) {
+		s := factory(t)
+
+		_, err := s.CreateUser(context.Background(), strings.Repeat("é", 100), "alice@example.com")
+		assert.NoError(t, err)
+	})
+}
+
+// names returns the names of users, in order
+func names(users []model.User) []string {
+	result := make([]string, len(users))
+	for i, user := range users {
+		result[i] = user.Name
+	}
+	return result
+}
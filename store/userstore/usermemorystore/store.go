@@ -1,37 +1,79 @@
 package usermemorystore
 
 import (
+	"cmp"
 	"context"
-	"errors"
+	"fmt"
+	"slices"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
 	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/memtransaction"
 	"github.com/google/uuid"
 )
 
 var (
-	ErrUserNotFound = errors.New("user not found")
+	// ErrUserNotFound is returned when no user has the requested ID
+	//
+	// Deprecated: use userstore.ErrNotFound
+	ErrUserNotFound = userstore.ErrNotFound
 )
 
+// Maximum lengths of the columns of the users table
+const (
+	maxNameLength  = 100
+	maxEmailLength = 255
+)
+
+// DeleteGuard checks that the user with the given ID can be deleted, e.g. that no post refers to it
+// The error it returns is returned by DeleteUser, which keeps the user
+type DeleteGuard func(ctx context.Context, id uuid.UUID) error
+
+// GuardedStore is the userstore.Store returned by New
+// The in-memory stores referring to users register their DeleteGuard with it, like the foreign keys of the database
+type GuardedStore interface {
+	userstore.Store
+
+	// AddDeleteGuard registers guard, called by DeleteUser before deleting a user
+	AddDeleteGuard(guard DeleteGuard)
+
+	// LockUser calls fn if the user with the given ID exists, DeleteUser waiting for fn to return,
+	// and returns userstore.ErrNotFound otherwise
+	// The stores referring to users insert their rows in fn, so that the guards of DeleteUser see them;
+	// fn must not call the methods of the store
+	LockUser(id uuid.UUID, fn func() error) error
+}
+
 type memoryStore struct {
-	mu    sync.RWMutex
-	users map[uuid.UUID]model.User
+	mu     sync.RWMutex
+	users  map[uuid.UUID]model.User
+	guards []DeleteGuard
 }
 
 // New creates a new in-memory implementation of userstore.Store
+// It is a GuardedStore, deleting the users that the stores registered with it allow only
 func New() userstore.Store {
 	return &memoryStore{
 		users: make(map[uuid.UUID]model.User),
 	}
 }
 
-func (s *memoryStore) CreateUser(_ context.Context, name, email string) (model.User, error) {
+func (s *memoryStore) CreateUser(ctx context.Context, name, email string) (model.User, error) {
+	if err := validate(name, email); err != nil {
+		return model.User{}, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	now := time.Now()
+	if s.emailTaken(email, uuid.Nil) {
+		return model.User{}, userstore.ErrDuplicateEmail
+	}
+
+	now := currentTime()
 	user := model.User{
 		ID:        uuid.New(),
 		Name:      name,
@@ -40,6 +82,10 @@ func (s *memoryStore) CreateUser(_ context.Context, name, email string) (model.U
 		UpdatedAt: now,
 	}
 
+	if err := memtransaction.RecordUndo(ctx, s.restore(user.ID, model.User{}, false)); err != nil {
+		return model.User{}, err
+	}
+
 	s.users[user.ID] = user
 	return user, nil
 }
@@ -50,7 +96,7 @@ func (s *memoryStore) GetUser(_ context.Context, id uuid.UUID) (model.User, erro
 
 	user, exists := s.users[id]
 	if !exists {
-		return model.User{}, ErrUserNotFound
+		return model.User{}, userstore.ErrNotFound
 	}
 
 	return user, nil
@@ -65,34 +111,117 @@ func (s *memoryStore) ListUsers(_ context.Context) ([]model.User, error) {
 		users = append(users, user)
 	}
 
+	slices.SortFunc(users, func(a, b model.User) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
 	return users, nil
 }
 
-func (s *memoryStore) UpdateUser(_ context.Context, id uuid.UUID, name, email string) (model.User, error) {
+func (s *memoryStore) UpdateUser(ctx context.Context, id uuid.UUID, name, email string) (model.User, error) {
+	if err := validate(name, email); err != nil {
+		return model.User{}, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	user, exists := s.users[id]
 	if !exists {
-		return model.User{}, ErrUserNotFound
+		return model.User{}, userstore.ErrNotFound
+	}
+
+	if s.emailTaken(email, id) {
+		return model.User{}, userstore.ErrDuplicateEmail
+	}
+
+	if err := memtransaction.RecordUndo(ctx, s.restore(id, user, true)); err != nil {
+		return model.User{}, err
 	}
 
 	user.Name = name
 	user.Email = email
-	user.UpdatedAt = time.Now()
+	user.UpdatedAt = currentTime()
 
 	s.users[id] = user
 	return user, nil
 }
 
-func (s *memoryStore) DeleteUser(_ context.Context, id uuid.UUID) error {
+func (s *memoryStore) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.users[id]; !exists {
-		return ErrUserNotFound
+	user, exists := s.users[id]
+	if !exists {
+		return userstore.ErrNotFound
+	}
+
+	for _, guard := range s.guards {
+		if err := guard(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	if err := memtransaction.RecordUndo(ctx, s.restore(id, user, true)); err != nil {
+		return err
 	}
 
 	delete(s.users, id)
 	return nil
 }
+
+func (s *memoryStore) AddDeleteGuard(guard DeleteGuard) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.guards = append(s.guards, guard)
+}
+
+func (s *memoryStore) LockUser(id uuid.UUID, fn func() error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.users[id]; !exists {
+		return userstore.ErrNotFound
+	}
+	return fn()
+}
+
+// emailTaken reports whether a user other than the one with the given ID uses email
+func (s *memoryStore) emailTaken(email string, id uuid.UUID) bool {
+	for _, user := range s.users {
+		if user.Email == email && user.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the constraints of the users table that do not depend on other rows
+func validate(name, email string) error {
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return fmt.Errorf("%w: name is longer than %d characters", model.ErrInvalidInput, maxNameLength)
+	}
+	if utf8.RuneCountInString(email) > maxEmailLength {
+		return fmt.Errorf("%w: email is longer than %d characters", model.ErrInvalidInput, maxEmailLength)
+	}
+	return nil
+}
+
+// restore returns a function that puts back the given version of a user, or removes the user if it did not exist
+func (s *memoryStore) restore(id uuid.UUID, user model.User, existed bool) func() {
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if existed {
+			s.users[id] = user
+		} else {
+			delete(s.users, id)
+		}
+	}
+}
+
+// currentTime returns the current time with the precision and location of a PostgreSQL TIMESTAMP
+func currentTime() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
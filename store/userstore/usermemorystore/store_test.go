@@ -0,0 +1,74 @@
+package usermemorystore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/storetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/memtransaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSuite(t *testing.T) {
+	storetest.RunStoreSuite(t, func(t *testing.T) userstore.Store {
+		return New()
+	})
+}
+
+func TestStore_Rollback(t *testing.T) {
+	tests := map[string]struct {
+		write func(ctx context.Context, s userstore.Store, alice model.User) error
+	}{
+		"create": {
+			write: func(ctx context.Context, s userstore.Store, _ model.User) error {
+				_, err := s.CreateUser(ctx, "bob", "bob@example.com")
+				return err
+			},
+		},
+		"update": {
+			write: func(ctx context.Context, s userstore.Store, alice model.User) error {
+				_, err := s.UpdateUser(ctx, alice.ID, "alicia", "alicia@example.com")
+				return err
+			},
+		},
+		"delete": {
+			write: func(ctx context.Context, s userstore.Store, alice model.User) error {
+				return s.DeleteUser(ctx, alice.ID)
+			},
+		},
+		"several writes are reverted newest first": {
+			write: func(ctx context.Context, s userstore.Store, alice model.User) error {
+				if _, err := s.UpdateUser(ctx, alice.ID, "alicia", "alicia@example.com"); err != nil {
+					return err
+				}
+				if _, err := s.UpdateUser(ctx, alice.ID, "ali", "ali@example.com"); err != nil {
+					return err
+				}
+				return s.DeleteUser(ctx, alice.ID)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := New()
+			alice, err := s.CreateUser(context.Background(), "alice", "alice@example.com")
+			require.NoError(t, err)
+
+			errFn := errors.New("fn failed")
+			err = memtransaction.New().ExecTx(context.Background(), func(ctx context.Context) error {
+				require.NoError(t, tt.write(ctx, s, alice))
+				return errFn
+			})
+			require.ErrorIs(t, err, errFn)
+
+			users, err := s.ListUsers(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, []model.User{alice}, users)
+		})
+	}
+}
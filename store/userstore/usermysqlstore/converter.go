@@ -0,0 +1,41 @@
+package usermysqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/mysqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/mysqlerr"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+)
+
+// Converts from mysqldb.User to model.User
+func toModelUser(dbUser mysqldb.User) model.User {
+	return model.User{
+		ID:        dbUser.ID,
+		Name:      dbUser.Name,
+		Email:     dbUser.Email,
+		CreatedAt: dbUser.CreatedAt,
+		UpdatedAt: dbUser.UpdatedAt,
+	}
+}
+
+// Converts from mysqldb.User slice to model.User slice
+func toModelUserList(dbUsers []mysqldb.User) []model.User {
+	users := make([]model.User, len(dbUsers))
+	for i, dbUser := range dbUsers {
+		users[i] = toModelUser(dbUser)
+	}
+	return users
+}
+
+// Converts errors returned by the queries to the errors documented by userstore.Store
+// The original error stays in the chain
+func toStoreError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", userstore.ErrNotFound, err)
+	}
+	return mysqlerr.Translate(err)
+}
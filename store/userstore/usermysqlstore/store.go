@@ -0,0 +1,95 @@
+package usermysqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/mysqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txqueries"
+	"github.com/google/uuid"
+)
+
+type mysqlStore struct {
+	q *txqueries.Queries[*sql.Tx, *mysqldb.Queries]
+}
+
+// New creates a new MySQL implementation of userstore.Store
+// It runs in the *sql.Tx carried by the context, such as the one started by mysqltransaction.Manager
+func New(q *mysqldb.Queries) userstore.Store {
+	return &mysqlStore{q: txqueries.Bind(q, q.WithTx)}
+}
+
+func (s *mysqlStore) CreateUser(ctx context.Context, name, email string) (model.User, error) {
+	dbParams := mysqldb.CreateUserParams{
+		ID:    uuid.New(),
+		Name:  name,
+		Email: email,
+	}
+
+	q := s.q.Get(ctx)
+	if err := q.CreateUser(ctx, dbParams); err != nil {
+		return model.User{}, toStoreError(err)
+	}
+
+	// MySQL has no RETURNING clause, so the row is read back to get the timestamps set by the database
+	dbUser, err := q.GetUser(ctx, dbParams.ID)
+	if err != nil {
+		return model.User{}, toStoreError(err)
+	}
+
+	return toModelUser(dbUser), nil
+}
+
+func (s *mysqlStore) GetUser(ctx context.Context, id uuid.UUID) (model.User, error) {
+	dbUser, err := s.q.Get(ctx).GetUser(ctx, id)
+	if err != nil {
+		return model.User{}, toStoreError(err)
+	}
+
+	return toModelUser(dbUser), nil
+}
+
+func (s *mysqlStore) ListUsers(ctx context.Context) ([]model.User, error) {
+	dbUsers, err := s.q.Get(ctx).ListUsers(ctx)
+	if err != nil {
+		return nil, toStoreError(err)
+	}
+
+	return toModelUserList(dbUsers), nil
+}
+
+func (s *mysqlStore) UpdateUser(ctx context.Context, id uuid.UUID, name, email string) (model.User, error) {
+	dbParams := mysqldb.UpdateUserParams{
+		ID:    id,
+		Name:  name,
+		Email: email,
+	}
+
+	q := s.q.Get(ctx)
+	if err := q.UpdateUser(ctx, dbParams); err != nil {
+		return model.User{}, toStoreError(err)
+	}
+
+	// The number of affected rows cannot tell a missing user from an unchanged one,
+	// so a missing user is reported by reading the row back
+	dbUser, err := q.GetUser(ctx, id)
+	if err != nil {
+		return model.User{}, toStoreError(err)
+	}
+
+	return toModelUser(dbUser), nil
+}
+
+func (s *mysqlStore) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	rows, err := s.q.Get(ctx).DeleteUser(ctx, id)
+	if err != nil {
+		return toStoreError(err)
+	}
+	if rows == 0 {
+		return userstore.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package usermysqlstore
+
+import (
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/mysqltest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/mysqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/storetest"
+)
+
+func TestStoreSuite(t *testing.T) {
+	storetest.RunStoreSuite(t, func(t *testing.T) userstore.Store {
+		mysqltest.SkipInMemory(t, "does not name the duplicate key", "UniqueEmail/")
+		mysqltest.SkipInMemory(t, "reports too long values as unknown errors", "InvalidInput/")
+		return New(mysqldb.New(mysqltest.NewDB(t)))
+	})
+}
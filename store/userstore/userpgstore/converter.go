@@ -1,8 +1,14 @@
 package userpgstore
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
 	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/db"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/pgerr"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/jackc/pgx/v5"
 )
 
 // Converts from db.User to model.User
@@ -24,3 +30,12 @@ func toModelUserList(dbUsers []db.User) []model.User {
 	}
 	return users
 }
+
+// Converts errors returned by the queries to the errors documented by userstore.Store
+// The original error stays in the chain
+func toStoreError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %w", userstore.ErrNotFound, err)
+	}
+	return pgerr.Translate(err)
+}
@@ -6,16 +6,18 @@ import (
 	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
 	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/db"
 	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txqueries"
 	"github.com/google/uuid"
 )
 
 type pgStore struct {
-	q *db.Queries
+	q *txqueries.Queries[db.DBTX, *db.Queries]
 }
 
 // New creates a new PostgreSQL implementation of userstore.Store
+// It runs in the pgx.Tx carried by the context, such as the one started by pgxtransaction.Manager
 func New(q *db.Queries) userstore.Store {
-	return &pgStore{q: q}
+	return &pgStore{q: txqueries.Bind(q, q.WithTx)}
 }
 
 func (s *pgStore) CreateUser(ctx context.Context, name, email string) (model.User, error) {
@@ -24,27 +26,27 @@ func (s *pgStore) CreateUser(ctx context.Context, name, email string) (model.Use
 		Email: email,
 	}
 
-	dbUser, err := s.q.CreateUser(ctx, dbParams)
+	dbUser, err := s.q.Get(ctx).CreateUser(ctx, dbParams)
 	if err != nil {
-		return model.User{}, err
+		return model.User{}, toStoreError(err)
 	}
 
 	return toModelUser(dbUser), nil
 }
 
 func (s *pgStore) GetUser(ctx context.Context, id uuid.UUID) (model.User, error) {
-	dbUser, err := s.q.GetUser(ctx, id)
+	dbUser, err := s.q.Get(ctx).GetUser(ctx, id)
 	if err != nil {
-		return model.User{}, err
+		return model.User{}, toStoreError(err)
 	}
 
 	return toModelUser(dbUser), nil
 }
 
 func (s *pgStore) ListUsers(ctx context.Context) ([]model.User, error) {
-	dbUsers, err := s.q.ListUsers(ctx)
+	dbUsers, err := s.q.Get(ctx).ListUsers(ctx)
 	if err != nil {
-		return nil, err
+		return nil, toStoreError(err)
 	}
 
 	return toModelUserList(dbUsers), nil
@@ -57,14 +59,22 @@ func (s *pgStore) UpdateUser(ctx context.Context, id uuid.UUID, name, email stri
 		Email: email,
 	}
 
-	dbUser, err := s.q.UpdateUser(ctx, dbParams)
+	dbUser, err := s.q.Get(ctx).UpdateUser(ctx, dbParams)
 	if err != nil {
-		return model.User{}, err
+		return model.User{}, toStoreError(err)
 	}
 
 	return toModelUser(dbUser), nil
 }
 
 func (s *pgStore) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	return s.q.DeleteUser(ctx, id)
+	rows, err := s.q.Get(ctx).DeleteUser(ctx, id)
+	if err != nil {
+		return toStoreError(err)
+	}
+	if rows == 0 {
+		return userstore.ErrNotFound
+	}
+
+	return nil
 }
@@ -0,0 +1,16 @@
+package userpgstore
+
+import (
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/pgtest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/db"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/storetest"
+)
+
+func TestStoreSuite(t *testing.T) {
+	storetest.RunStoreSuite(t, func(t *testing.T) userstore.Store {
+		return New(db.New(pgtest.NewPool(t)))
+	})
+}
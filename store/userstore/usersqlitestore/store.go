@@ -0,0 +1,82 @@
+package usersqlitestore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqlitedb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txqueries"
+	"github.com/google/uuid"
+)
+
+type sqliteStore struct {
+	q *txqueries.Queries[*sql.Tx, *sqlitedb.Queries]
+}
+
+// New creates a new SQLite implementation of userstore.Store
+// It runs in the *sql.Tx carried by the context, such as the one started by sqlitetransaction.Manager
+func New(q *sqlitedb.Queries) userstore.Store {
+	return &sqliteStore{q: txqueries.Bind(q, q.WithTx)}
+}
+
+func (s *sqliteStore) CreateUser(ctx context.Context, name, email string) (model.User, error) {
+	dbParams := sqlitedb.CreateUserParams{
+		ID:    uuid.New(),
+		Name:  name,
+		Email: email,
+	}
+
+	dbUser, err := s.q.Get(ctx).CreateUser(ctx, dbParams)
+	if err != nil {
+		return model.User{}, toStoreError(err)
+	}
+
+	return toModelUser(dbUser), nil
+}
+
+func (s *sqliteStore) GetUser(ctx context.Context, id uuid.UUID) (model.User, error) {
+	dbUser, err := s.q.Get(ctx).GetUser(ctx, id)
+	if err != nil {
+		return model.User{}, toStoreError(err)
+	}
+
+	return toModelUser(dbUser), nil
+}
+
+func (s *sqliteStore) ListUsers(ctx context.Context) ([]model.User, error) {
+	dbUsers, err := s.q.Get(ctx).ListUsers(ctx)
+	if err != nil {
+		return nil, toStoreError(err)
+	}
+
+	return toModelUserList(dbUsers), nil
+}
+
+func (s *sqliteStore) UpdateUser(ctx context.Context, id uuid.UUID, name, email string) (model.User, error) {
+	dbParams := sqlitedb.UpdateUserParams{
+		ID:    id,
+		Name:  name,
+		Email: email,
+	}
+
+	dbUser, err := s.q.Get(ctx).UpdateUser(ctx, dbParams)
+	if err != nil {
+		return model.User{}, toStoreError(err)
+	}
+
+	return toModelUser(dbUser), nil
+}
+
+func (s *sqliteStore) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	rows, err := s.q.Get(ctx).DeleteUser(ctx, id)
+	if err != nil {
+		return toStoreError(err)
+	}
+	if rows == 0 {
+		return userstore.ErrNotFound
+	}
+
+	return nil
+}
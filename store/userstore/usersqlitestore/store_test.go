@@ -0,0 +1,16 @@
+package usersqlitestore
+
+import (
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/sqlitetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqlitedb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/storetest"
+)
+
+func TestStoreSuite(t *testing.T) {
+	storetest.RunStoreSuite(t, func(t *testing.T) userstore.Store {
+		return New(sqlitedb.New(sqlitetest.NewDB(t)))
+	})
+}
@@ -0,0 +1,41 @@
+package usersqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/pgerr"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+)
+
+// Converts from sqldb.User to model.User
+func toModelUser(dbUser sqldb.User) model.User {
+	return model.User{
+		ID:        dbUser.ID,
+		Name:      dbUser.Name,
+		Email:     dbUser.Email,
+		CreatedAt: dbUser.CreatedAt,
+		UpdatedAt: dbUser.UpdatedAt,
+	}
+}
+
+// Converts from sqldb.User slice to model.User slice
+func toModelUserList(dbUsers []sqldb.User) []model.User {
+	users := make([]model.User, len(dbUsers))
+	for i, dbUser := range dbUsers {
+		users[i] = toModelUser(dbUser)
+	}
+	return users
+}
+
+// Converts errors returned by the queries to the errors documented by userstore.Store
+// The original error stays in the chain
+func toStoreError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", userstore.ErrNotFound, err)
+	}
+	return pgerr.Translate(err)
+}
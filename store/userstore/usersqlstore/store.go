@@ -0,0 +1,81 @@
+package usersqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqldb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txqueries"
+	"github.com/google/uuid"
+)
+
+type sqlStore struct {
+	q *txqueries.Queries[*sql.Tx, *sqldb.Queries]
+}
+
+// New creates a new database/sql implementation of userstore.Store for PostgreSQL
+// It runs in the *sql.Tx carried by the context, such as the one started by sqltransaction.Manager
+func New(q *sqldb.Queries) userstore.Store {
+	return &sqlStore{q: txqueries.Bind(q, q.WithTx)}
+}
+
+func (s *sqlStore) CreateUser(ctx context.Context, name, email string) (model.User, error) {
+	dbParams := sqldb.CreateUserParams{
+		Name:  name,
+		Email: email,
+	}
+
+	dbUser, err := s.q.Get(ctx).CreateUser(ctx, dbParams)
+	if err != nil {
+		return model.User{}, toStoreError(err)
+	}
+
+	return toModelUser(dbUser), nil
+}
+
+func (s *sqlStore) GetUser(ctx context.Context, id uuid.UUID) (model.User, error) {
+	dbUser, err := s.q.Get(ctx).GetUser(ctx, id)
+	if err != nil {
+		return model.User{}, toStoreError(err)
+	}
+
+	return toModelUser(dbUser), nil
+}
+
+func (s *sqlStore) ListUsers(ctx context.Context) ([]model.User, error) {
+	dbUsers, err := s.q.Get(ctx).ListUsers(ctx)
+	if err != nil {
+		return nil, toStoreError(err)
+	}
+
+	return toModelUserList(dbUsers), nil
+}
+
+func (s *sqlStore) UpdateUser(ctx context.Context, id uuid.UUID, name, email string) (model.User, error) {
+	dbParams := sqldb.UpdateUserParams{
+		ID:    id,
+		Name:  name,
+		Email: email,
+	}
+
+	dbUser, err := s.q.Get(ctx).UpdateUser(ctx, dbParams)
+	if err != nil {
+		return model.User{}, toStoreError(err)
+	}
+
+	return toModelUser(dbUser), nil
+}
+
+func (s *sqlStore) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	rows, err := s.q.Get(ctx).DeleteUser(ctx, id)
+	if err != nil {
+		return toStoreError(err)
+	}
+	if rows == 0 {
+		return userstore.ErrNotFound
+	}
+
+	return nil
+}
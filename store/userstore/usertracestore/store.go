@@ -0,0 +1,82 @@
+// Package usertracestore traces the calls to a userstore.Store with OpenTelemetry
+package usertracestore
+
+import (
+	"context"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/model"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans created by this package
+const ScopeName = "github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usertracestore"
+
+type traceStore struct {
+	next   userstore.Store
+	tracer trace.Tracer
+}
+
+// New creates a userstore.Store running each call of next in a span named after the method, e.g. "userstore.GetUser"
+// The spans use the tracer provider tp, or the global one if tp is nil
+func New(next userstore.Store, tp trace.TracerProvider) userstore.Store {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &traceStore{
+		next:   next,
+		tracer: tp.Tracer(ScopeName),
+	}
+}
+
+func (s *traceStore) CreateUser(ctx context.Context, name, email string) (user model.User, err error) {
+	ctx, span := s.start(ctx, "userstore.CreateUser")
+	defer func() { end(span, err) }()
+
+	return s.next.CreateUser(ctx, name, email)
+}
+
+func (s *traceStore) GetUser(ctx context.Context, id uuid.UUID) (user model.User, err error) {
+	ctx, span := s.start(ctx, "userstore.GetUser")
+	defer func() { end(span, err) }()
+
+	return s.next.GetUser(ctx, id)
+}
+
+func (s *traceStore) ListUsers(ctx context.Context) (users []model.User, err error) {
+	ctx, span := s.start(ctx, "userstore.ListUsers")
+	defer func() { end(span, err) }()
+
+	return s.next.ListUsers(ctx)
+}
+
+func (s *traceStore) UpdateUser(ctx context.Context, id uuid.UUID, name, email string) (user model.User, err error) {
+	ctx, span := s.start(ctx, "userstore.UpdateUser")
+	defer func() { end(span, err) }()
+
+	return s.next.UpdateUser(ctx, id, name, email)
+}
+
+func (s *traceStore) DeleteUser(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := s.start(ctx, "userstore.DeleteUser")
+	defer func() { end(span, err) }()
+
+	return s.next.DeleteUser(ctx, id)
+}
+
+// start starts the span of a call
+func (s *traceStore) start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal))
+}
+
+// end records the error of a call, if any, and ends its span
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,55 @@
+package usertracestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/storetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usermemorystore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStoreSuite(t *testing.T) {
+	storetest.RunStoreSuite(t, func(t *testing.T) userstore.Store {
+		return New(usermemorystore.New(), nil)
+	})
+}
+
+func TestStore_Spans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	s := New(usermemorystore.New(), tp)
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, "alice", "alice@example.com")
+	require.NoError(t, err)
+	_, err = s.GetUser(ctx, uuid.New())
+	require.ErrorIs(t, err, userstore.ErrNotFound)
+	_, err = s.ListUsers(ctx)
+	require.NoError(t, err)
+	_, err = s.UpdateUser(ctx, user.ID, "alicia", "alicia@example.com")
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteUser(ctx, user.ID))
+
+	var names []string
+	var statuses []codes.Code
+	for _, span := range exporter.GetSpans() {
+		names = append(names, span.Name)
+		statuses = append(statuses, span.Status.Code)
+	}
+	assert.Equal(t, []string{
+		"userstore.CreateUser",
+		"userstore.GetUser",
+		"userstore.ListUsers",
+		"userstore.UpdateUser",
+		"userstore.DeleteUser",
+	}, names)
+	assert.Equal(t, []codes.Code{codes.Unset, codes.Error, codes.Unset, codes.Unset, codes.Unset}, statuses)
+}
@@ -0,0 +1,67 @@
+package transaction
+
+// Config holds the settings shared by Manager implementations
+type Config struct {
+	// RetryPolicy is the retry policy of the transactions started by ExecTx
+	// The zero value disables retries
+	RetryPolicy RetryPolicy
+
+	// PanicAsError makes ExecTx return a *PanicError instead of re-panicking
+	// once the transaction has been rolled back
+	PanicAsError bool
+
+	// Timeouts bounds the transactions started by ExecTx
+	// Begin applies the PostgreSQL settings but not the Transaction deadline, which needs ExecTx
+	// The zero value sets no timeout
+	Timeouts Timeouts
+}
+
+// Option configures a Manager
+type Option func(*Config)
+
+// NewConfig returns a Config with the given options applied
+func NewConfig(opts ...Option) Config {
+	var cfg Config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithRetryPolicy sets the retry policy of the transactions started by ExecTx
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(cfg *Config) {
+		cfg.RetryPolicy = policy
+	}
+}
+
+// WithPanicAsError makes ExecTx return a *PanicError when the function panics,
+// instead of re-panicking once the transaction has been rolled back
+func WithPanicAsError() Option {
+	return func(cfg *Config) {
+		cfg.PanicAsError = true
+	}
+}
+
+// WithTimeouts sets the timeouts of the transactions started by the Manager
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(cfg *Config) {
+		cfg.Timeouts = timeouts
+	}
+}
+
+// RetryPolicyFor returns the retry policy of a transaction started with opts
+func (c Config) RetryPolicyFor(opts TxOptions) RetryPolicy {
+	if opts.Retry != nil {
+		return *opts.Retry
+	}
+	return c.RetryPolicy
+}
+
+// TimeoutsFor returns the timeouts of a transaction started with opts
+func (c Config) TimeoutsFor(opts TxOptions) Timeouts {
+	if opts.Timeouts != nil {
+		return *opts.Timeouts
+	}
+	return c.Timeouts
+}
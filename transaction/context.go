@@ -0,0 +1,61 @@
+package transaction
+
+import (
+	"context"
+	"time"
+)
+
+// txKey is a key for retrieving transaction from context
+type txKey struct{}
+
+// WithTx returns a copy of ctx carrying tx as the current transaction
+// Manager implementations call it so that stores can pick the transaction up again
+func WithTx(ctx context.Context, tx any) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the transaction carried by ctx if it is of type T
+func TxFromContext[T any](ctx context.Context) (T, bool) {
+	tx, ok := ctx.Value(txKey{}).(T)
+	return tx, ok
+}
+
+// Executor resolves the executor a store should run its queries on:
+// the transaction carried by ctx if it is of type T, otherwise fallback (typically the pool)
+func Executor[T any](ctx context.Context, fallback T) T {
+	if tx, ok := TxFromContext[T](ctx); ok {
+		return tx
+	}
+	return fallback
+}
+
+// nameKey is a key for retrieving the name of the transactions from context
+type nameKey struct{}
+
+// WithName returns a copy of ctx naming the transactions started with it, e.g. "create_user_with_post"
+// The name labels the metrics of the transactions; it does not change how they run
+func WithName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, nameKey{}, name)
+}
+
+// NameFromContext returns the name given to the transactions with WithName, or "" if there is none
+func NameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(nameKey{}).(string)
+	return name
+}
+
+// beginDurationKey is a key for retrieving how long the current transaction took to begin from context
+type beginDurationKey struct{}
+
+// withBeginDuration returns a copy of ctx recording that its transaction took d to begin
+func withBeginDuration(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, beginDurationKey{}, d)
+}
+
+// BeginDurationFromContext returns how long the transaction carried by ctx took to begin, if it was started by a Runner,
+// including the wait for a pooled connection and the statements setting the transaction up
+// Each attempt of a retried transaction begins a new transaction, with its own duration
+func BeginDurationFromContext(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Value(beginDurationKey{}).(time.Duration)
+	return d, ok
+}
@@ -0,0 +1,89 @@
+package transaction
+
+import (
+	"context"
+)
+
+// Do executes fn within a transaction with m.ExecTx and returns the value computed by fn
+// The zero value is returned with the error when the transaction fails, even if fn itself succeeded
+//
+//	user, err := transaction.Do(ctx, txManager, func(ctx context.Context) (model.User, error) {
+//		return userStore.CreateUser(ctx, name, email)
+//	})
+func Do[T any](ctx context.Context, m Manager, fn func(ctx context.Context) (T, error)) (T, error) {
+	return DoWithOptions(ctx, m, TxOptions{}, fn)
+}
+
+// DoWithOptions is like Do, but starts the transaction with m.ExecTxWithOptions and the given options
+// It falls back to m.ExecTx for the zero TxOptions, so that mocks only expecting ExecTx keep working with Do
+func DoWithOptions[T any](ctx context.Context, m Manager, opts TxOptions, fn func(ctx context.Context) (T, error)) (T, error) {
+	return do(func(fn func(ctx context.Context) error) error {
+		return execTx(ctx, m, opts, fn)
+	}, fn)
+}
+
+// DoReadOnly is like Do, but runs fn with m.ExecReadOnly
+func DoReadOnly[T any](ctx context.Context, m Manager, fn func(ctx context.Context) (T, error)) (T, error) {
+	return do(func(fn func(ctx context.Context) error) error {
+		return m.ExecReadOnly(ctx, fn)
+	}, fn)
+}
+
+// do runs fn with exec and returns the value computed by fn, or the zero value if exec fails
+func do[T any](exec func(fn func(ctx context.Context) error) error, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := exec(func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+// Do2 is like Do for functions computing two values
+func Do2[T1, T2 any](ctx context.Context, m Manager, fn func(ctx context.Context) (T1, T2, error)) (T1, T2, error) {
+	return Do2WithOptions(ctx, m, TxOptions{}, fn)
+}
+
+// Do2WithOptions is like DoWithOptions for functions computing two values
+func Do2WithOptions[T1, T2 any](ctx context.Context, m Manager, opts TxOptions, fn func(ctx context.Context) (T1, T2, error)) (T1, T2, error) {
+	return do2(func(fn func(ctx context.Context) error) error {
+		return execTx(ctx, m, opts, fn)
+	}, fn)
+}
+
+// Do2ReadOnly is like DoReadOnly for functions computing two values
+func Do2ReadOnly[T1, T2 any](ctx context.Context, m Manager, fn func(ctx context.Context) (T1, T2, error)) (T1, T2, error) {
+	return do2(func(fn func(ctx context.Context) error) error {
+		return m.ExecReadOnly(ctx, fn)
+	}, fn)
+}
+
+// do2 is like do for functions computing two values
+func do2[T1, T2 any](exec func(fn func(ctx context.Context) error) error, fn func(ctx context.Context) (T1, T2, error)) (T1, T2, error) {
+	var result1 T1
+	var result2 T2
+	err := exec(func(ctx context.Context) error {
+		var err error
+		result1, result2, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		var zero1 T1
+		var zero2 T2
+		return zero1, zero2, err
+	}
+	return result1, result2, nil
+}
+
+// execTx runs fn with m.ExecTx for the zero TxOptions, and with m.ExecTxWithOptions otherwise
+func execTx(ctx context.Context, m Manager, opts TxOptions, fn func(ctx context.Context) error) error {
+	if opts == (TxOptions{}) {
+		return m.ExecTx(ctx, fn)
+	}
+	return m.ExecTxWithOptions(ctx, opts, fn)
+}
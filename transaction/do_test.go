@@ -0,0 +1,152 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeManager runs fn directly and records which method was called
+// It fails with commitErr after fn succeeds, standing for a failed commit
+type fakeManager struct {
+	Manager
+	calls     []string
+	opts      TxOptions
+	commitErr error
+}
+
+func (m *fakeManager) ExecTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls = append(m.calls, "ExecTx")
+	return m.run(ctx, fn)
+}
+
+func (m *fakeManager) ExecTxWithOptions(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	m.calls = append(m.calls, "ExecTxWithOptions")
+	m.opts = opts
+	return m.run(ctx, fn)
+}
+
+func (m *fakeManager) ExecReadOnly(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls = append(m.calls, "ExecReadOnly")
+	return m.run(ctx, fn)
+}
+
+func (m *fakeManager) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	return m.commitErr
+}
+
+func TestDo(t *testing.T) {
+	errFn := errors.New("fn failed")
+	errCommit := errors.New("commit failed")
+
+	tests := map[string]struct {
+		fnErr          error
+		commitErr      error
+		expectedResult int
+		expectedError  error
+	}{
+		"success - returns the value of fn": {
+			expectedResult: 42,
+		},
+		"error - fn fails": {
+			fnErr:         errFn,
+			expectedError: errFn,
+		},
+		"error - commit fails after fn succeeded": {
+			commitErr:     errCommit,
+			expectedError: errCommit,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &fakeManager{commitErr: tt.commitErr}
+
+			result, err := Do(context.Background(), m, func(ctx context.Context) (int, error) {
+				return 42, tt.fnErr
+			})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedResult, result)
+			assert.Equal(t, []string{"ExecTx"}, m.calls)
+		})
+	}
+}
+
+func TestDo2(t *testing.T) {
+	errFn := errors.New("fn failed")
+
+	tests := map[string]struct {
+		fnErr           error
+		expectedResult1 string
+		expectedResult2 int
+		expectedError   error
+	}{
+		"success - returns both values of fn": {
+			expectedResult1: "a",
+			expectedResult2: 1,
+		},
+		"error - fn fails": {
+			fnErr:         errFn,
+			expectedError: errFn,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &fakeManager{}
+
+			result1, result2, err := Do2(context.Background(), m, func(ctx context.Context) (string, int, error) {
+				return "a", 1, tt.fnErr
+			})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedResult1, result1)
+			assert.Equal(t, tt.expectedResult2, result2)
+		})
+	}
+}
+
+func TestDoWithOptions(t *testing.T) {
+	opts := TxOptions{Isolation: LevelSerializable, ReadOnly: true}
+	m := &fakeManager{}
+
+	result, err := DoWithOptions(context.Background(), m, opts, func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, result)
+	assert.Equal(t, []string{"ExecTxWithOptions"}, m.calls)
+	assert.Equal(t, opts, m.opts)
+
+	_, _, err = Do2WithOptions(context.Background(), m, TxOptions{}, func(ctx context.Context) (int, int, error) {
+		return 1, 2, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ExecTxWithOptions", "ExecTx"}, m.calls, "zero options fall back to ExecTx")
+}
+
+func TestDoReadOnly(t *testing.T) {
+	m := &fakeManager{}
+
+	result, err := DoReadOnly(context.Background(), m, func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, result)
+
+	_, _, err = Do2ReadOnly(context.Background(), m, func(ctx context.Context) (int, int, error) {
+		return 1, 2, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ExecReadOnly", "ExecReadOnly"}, m.calls)
+}
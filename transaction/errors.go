@@ -0,0 +1,64 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoTransaction is returned when a transaction is required but the context carries none
+	ErrNoTransaction = errors.New("transaction not found in context")
+
+	// ErrTransactionExists is returned when the context carries a transaction although none is allowed
+	ErrTransactionExists = errors.New("transaction already exists in context")
+
+	// ErrTxDone is returned when committing or rolling back a transaction that has already been committed or rolled back
+	// It wraps the driver error, such as sql.ErrTxDone or pgx.ErrTxClosed
+	ErrTxDone = errors.New("transaction has already been committed or rolled back")
+
+	// ErrReadOnly is returned by the in-memory stores when writing in a read-only transaction
+	// Database drivers report their own error instead, such as SQLSTATE 25006 for PostgreSQL
+	ErrReadOnly = errors.New("cannot write in a read-only transaction")
+
+	// ErrRolledBack is returned to the middlewares of Chain when a transaction started by Begin is rolled back by Rollback
+	ErrRolledBack = errors.New("transaction rolled back")
+)
+
+// RollbackError is returned when a transaction failed and rolling it back failed as well
+// Both errors can be matched with errors.Is and errors.As
+type RollbackError struct {
+	// Err is the error that caused the rollback
+	Err error
+
+	// RollbackErr is the error returned by the rollback
+	RollbackErr error
+}
+
+func (e *RollbackError) Error() string {
+	return fmt.Sprintf("%v (rollback failed: %v)", e.Err, e.RollbackErr)
+}
+
+func (e *RollbackError) Unwrap() []error {
+	return []error{e.Err, e.RollbackErr}
+}
+
+// CommitError is returned when committing a transaction, or releasing a savepoint, failed
+type CommitError struct {
+	Err error
+}
+
+func (e *CommitError) Error() string {
+	return fmt.Sprintf("commit transaction: %v", e.Err)
+}
+
+func (e *CommitError) Unwrap() error {
+	return e.Err
+}
+
+// WithRollback returns err, or a *RollbackError carrying both errors if rbErr is not nil
+func WithRollback(err, rbErr error) error {
+	if rbErr == nil {
+		return err
+	}
+	return &RollbackError{Err: err, RollbackErr: rbErr}
+}
@@ -0,0 +1,30 @@
+package transaction
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRollback(t *testing.T) {
+	errCause := errors.New("insert failed")
+	errRollback := errors.New("connection reset")
+
+	t.Run("returns the cause when the rollback succeeded", func(t *testing.T) {
+		assert.Same(t, errCause, WithRollback(errCause, nil))
+	})
+
+	t.Run("keeps both errors when the rollback failed", func(t *testing.T) {
+		err := WithRollback(errCause, errRollback)
+
+		var rbErr *RollbackError
+		if assert.ErrorAs(t, err, &rbErr) {
+			assert.Same(t, errCause, rbErr.Err)
+			assert.Same(t, errRollback, rbErr.RollbackErr)
+		}
+		assert.ErrorIs(t, err, errCause)
+		assert.ErrorIs(t, err, errRollback)
+		assert.EqualError(t, err, "insert failed (rollback failed: connection reset)")
+	})
+}
@@ -0,0 +1,202 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// hooksKey is a key for retrieving the hook scope from context
+type hooksKey struct{}
+
+// Hooks holds the callbacks registered for a transaction or one of its savepoints
+//
+// Callbacks run in registration order:
+//   - before-commit hooks run inside the transaction right before it commits; an error vetoes the commit
+//   - after-commit hooks run once the transaction has committed
+//   - after-rollback hooks run once the transaction, or the savepoint they were registered in, has rolled back
+//
+// Releasing a savepoint hands its hooks over to the enclosing scope, so they only run
+// when the outermost transaction commits or rolls back
+type Hooks struct {
+	mu            sync.Mutex
+	parent        *Hooks
+	done          bool
+	beforeCommit  []func(ctx context.Context) error
+	afterCommit   []func(ctx context.Context)
+	afterRollback []func(ctx context.Context)
+}
+
+// WithHooks returns a copy of ctx carrying a new hook scope for a transaction
+// Manager implementations call it when they start a new transaction
+func WithHooks(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hooksKey{}, &Hooks{})
+}
+
+// WithNestedHooks returns a copy of ctx carrying a new hook scope nested in the one already carried by ctx
+// Manager implementations call it when they create a savepoint
+func WithNestedHooks(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hooksKey{}, &Hooks{parent: HooksFromContext(ctx)})
+}
+
+// HooksFromContext returns the hook scope carried by ctx, or nil if there is none
+// All methods of Hooks can be called on a nil scope
+func HooksFromContext(ctx context.Context) *Hooks {
+	hooks, _ := ctx.Value(hooksKey{}).(*Hooks)
+	return hooks
+}
+
+// BeforeCommit registers fn to run inside the transaction carried by ctx right before it commits
+// If fn returns an error, the transaction is rolled back instead and the error is returned by Commit
+// It returns ErrNoTransaction if ctx carries no transaction
+func BeforeCommit(ctx context.Context, fn func(ctx context.Context) error) error {
+	return register(ctx, func(h *Hooks) { h.beforeCommit = append(h.beforeCommit, fn) })
+}
+
+// OnCommit registers fn to run once the transaction carried by ctx has committed
+// fn receives a context that no longer carries the transaction
+// It returns ErrNoTransaction if ctx carries no transaction
+func OnCommit(ctx context.Context, fn func(ctx context.Context)) error {
+	return register(ctx, func(h *Hooks) { h.afterCommit = append(h.afterCommit, fn) })
+}
+
+// OnRollback registers fn to run once the transaction carried by ctx has rolled back,
+// or once the savepoint carried by ctx has been rolled back to
+// fn receives a context that no longer carries the transaction
+// It returns ErrNoTransaction if ctx carries no transaction
+func OnRollback(ctx context.Context, fn func(ctx context.Context)) error {
+	return register(ctx, func(h *Hooks) { h.afterRollback = append(h.afterRollback, fn) })
+}
+
+// register adds a hook to the scope carried by ctx
+func register(ctx context.Context, add func(h *Hooks)) error {
+	h := HooksFromContext(ctx)
+	if h == nil {
+		return ErrNoTransaction
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.done {
+		return ErrNoTransaction
+	}
+	add(h)
+	return nil
+}
+
+// Commit completes the scope with commit
+// For a transaction, the before-commit hooks run first and the after-commit hooks once commit succeeded;
+// if a before-commit hook fails, rollback is called instead and the after-rollback hooks run
+// For a savepoint, commit releases it and the hooks are handed over to the enclosing scope
+func (h *Hooks) Commit(ctx context.Context, commit func() error, rollback func() error) error {
+	if h != nil && h.parent != nil {
+		if err := commit(); err != nil {
+			return err
+		}
+		h.handOver()
+		return nil
+	}
+
+	// Before-commit hooks may register further hooks, which run as well
+	for i := 0; ; i++ {
+		fn, ok := h.beforeCommitHook(i)
+		if !ok {
+			break
+		}
+
+		if err := fn(ctx); err != nil {
+			return WithRollback(fmt.Errorf("before commit hook: %w", err), h.Rollback(ctx, rollback))
+		}
+	}
+
+	if err := commit(); err != nil {
+		// A transaction whose commit failed has been rolled back
+		h.runAfterRollback(ctx)
+		return err
+	}
+
+	h.runAfterCommit(ctx)
+	return nil
+}
+
+// Rollback completes the scope with rollback, then runs the after-rollback hooks of the scope
+func (h *Hooks) Rollback(ctx context.Context, rollback func() error) error {
+	err := rollback()
+	h.runAfterRollback(ctx)
+	return err
+}
+
+// beforeCommitHook returns the i-th before-commit hook, if any
+func (h *Hooks) beforeCommitHook(i int) (func(ctx context.Context) error, bool) {
+	if h == nil {
+		return nil, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if i >= len(h.beforeCommit) {
+		return nil, false
+	}
+	return h.beforeCommit[i], true
+}
+
+// handOver moves the hooks of a released savepoint to the enclosing scope
+func (h *Hooks) handOver() {
+	h.mu.Lock()
+	beforeCommit, afterCommit, afterRollback := h.beforeCommit, h.afterCommit, h.afterRollback
+	h.beforeCommit, h.afterCommit, h.afterRollback = nil, nil, nil
+	h.done = true
+	h.mu.Unlock()
+
+	p := h.parent
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.beforeCommit = append(p.beforeCommit, beforeCommit...)
+	p.afterCommit = append(p.afterCommit, afterCommit...)
+	p.afterRollback = append(p.afterRollback, afterRollback...)
+}
+
+// runAfterCommit runs the after-commit hooks once
+func (h *Hooks) runAfterCommit(ctx context.Context) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	hooks := h.afterCommit
+	h.finish()
+	h.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn(detach(ctx))
+	}
+}
+
+// runAfterRollback runs the after-rollback hooks once
+func (h *Hooks) runAfterRollback(ctx context.Context) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	hooks := h.afterRollback
+	h.finish()
+	h.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn(detach(ctx))
+	}
+}
+
+// finish marks the scope as completed and drops its hooks, so that they never run twice
+func (h *Hooks) finish() {
+	h.done = true
+	h.beforeCommit, h.afterCommit, h.afterRollback = nil, nil, nil
+}
+
+// detach returns a copy of ctx that no longer carries a transaction nor a hook scope
+func detach(ctx context.Context) context.Context {
+	return context.WithValue(context.WithValue(ctx, txKey{}, nil), hooksKey{}, nil)
+}
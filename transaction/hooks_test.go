@@ -0,0 +1,183 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder collects the events of a test in order
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) add(event string) func() error {
+	return func() error {
+		r.events = append(r.events, event)
+		return nil
+	}
+}
+
+// registerAll registers one hook of each kind, named after the scope
+func (r *recorder) registerAll(t *testing.T, ctx context.Context, scope string) {
+	t.Helper()
+
+	require.NoError(t, BeforeCommit(ctx, func(ctx context.Context) error {
+		r.events = append(r.events, scope+": before commit")
+		return nil
+	}))
+	require.NoError(t, OnCommit(ctx, func(ctx context.Context) {
+		r.events = append(r.events, scope+": after commit")
+	}))
+	require.NoError(t, OnRollback(ctx, func(ctx context.Context) {
+		r.events = append(r.events, scope+": after rollback")
+	}))
+}
+
+func TestHooks_Commit(t *testing.T) {
+	r := &recorder{}
+	ctx := WithHooks(context.Background())
+	r.registerAll(t, ctx, "tx")
+
+	err := HooksFromContext(ctx).Commit(ctx, r.add("commit"), r.add("rollback"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tx: before commit", "commit", "tx: after commit"}, r.events)
+}
+
+func TestHooks_Rollback(t *testing.T) {
+	r := &recorder{}
+	ctx := WithHooks(context.Background())
+	r.registerAll(t, ctx, "tx")
+
+	err := HooksFromContext(ctx).Rollback(ctx, r.add("rollback"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rollback", "tx: after rollback"}, r.events)
+}
+
+func TestHooks_BeforeCommitVeto(t *testing.T) {
+	errVeto := errors.New("veto")
+
+	r := &recorder{}
+	ctx := WithHooks(context.Background())
+	r.registerAll(t, ctx, "tx")
+	require.NoError(t, BeforeCommit(ctx, func(ctx context.Context) error {
+		return errVeto
+	}))
+
+	err := HooksFromContext(ctx).Commit(ctx, r.add("commit"), r.add("rollback"))
+
+	assert.ErrorIs(t, err, errVeto)
+	assert.Equal(t, []string{"tx: before commit", "rollback", "tx: after rollback"}, r.events)
+}
+
+func TestHooks_CommitFailure(t *testing.T) {
+	errCommit := errors.New("commit failed")
+
+	r := &recorder{}
+	ctx := WithHooks(context.Background())
+	r.registerAll(t, ctx, "tx")
+
+	err := HooksFromContext(ctx).Commit(ctx, func() error { return errCommit }, r.add("rollback"))
+
+	assert.ErrorIs(t, err, errCommit)
+	assert.Equal(t, []string{"tx: before commit", "tx: after rollback"}, r.events)
+}
+
+func TestHooks_Savepoint(t *testing.T) {
+	tests := map[string]struct {
+		releaseSavepoint bool
+		commitOuter      bool
+		expectedEvents   []string
+	}{
+		"released savepoint hooks run when the outer transaction commits": {
+			releaseSavepoint: true,
+			commitOuter:      true,
+			expectedEvents: []string{
+				"release",
+				"tx: before commit",
+				"sp: before commit",
+				"commit",
+				"tx: after commit",
+				"sp: after commit",
+			},
+		},
+		"released savepoint hooks run when the outer transaction rolls back": {
+			releaseSavepoint: true,
+			commitOuter:      false,
+			expectedEvents: []string{
+				"release",
+				"rollback",
+				"tx: after rollback",
+				"sp: after rollback",
+			},
+		},
+		"rolled back savepoint runs its rollback hooks and drops its commit hooks": {
+			releaseSavepoint: false,
+			commitOuter:      true,
+			expectedEvents: []string{
+				"rollback to savepoint",
+				"sp: after rollback",
+				"tx: before commit",
+				"commit",
+				"tx: after commit",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &recorder{}
+			ctx := WithHooks(context.Background())
+			r.registerAll(t, ctx, "tx")
+
+			spCtx := WithNestedHooks(ctx)
+			r.registerAll(t, spCtx, "sp")
+
+			if tt.releaseSavepoint {
+				require.NoError(t, HooksFromContext(spCtx).Commit(spCtx, r.add("release"), nil))
+			} else {
+				require.NoError(t, HooksFromContext(spCtx).Rollback(spCtx, r.add("rollback to savepoint")))
+			}
+
+			if tt.commitOuter {
+				require.NoError(t, HooksFromContext(ctx).Commit(ctx, r.add("commit"), r.add("rollback")))
+			} else {
+				require.NoError(t, HooksFromContext(ctx).Rollback(ctx, r.add("rollback")))
+			}
+
+			assert.Equal(t, tt.expectedEvents, r.events)
+		})
+	}
+}
+
+func TestHooks_Registration(t *testing.T) {
+	noop := func(ctx context.Context) {}
+
+	t.Run("fails without a transaction", func(t *testing.T) {
+		assert.ErrorIs(t, OnCommit(context.Background(), noop), ErrNoTransaction)
+	})
+
+	t.Run("fails once the transaction has completed", func(t *testing.T) {
+		ctx := WithHooks(context.Background())
+		require.NoError(t, HooksFromContext(ctx).Commit(ctx, func() error { return nil }, nil))
+
+		assert.ErrorIs(t, OnCommit(ctx, noop), ErrNoTransaction)
+	})
+
+	t.Run("after-commit hooks run outside the transaction", func(t *testing.T) {
+		ctx := WithTx(WithHooks(context.Background()), "tx")
+
+		var hookCtx context.Context
+		require.NoError(t, OnCommit(ctx, func(ctx context.Context) { hookCtx = ctx }))
+		require.NoError(t, HooksFromContext(ctx).Commit(ctx, func() error { return nil }, nil))
+
+		_, ok := TxFromContext[string](hookCtx)
+		assert.False(t, ok)
+		assert.ErrorIs(t, OnCommit(hookCtx, noop), ErrNoTransaction)
+	})
+}
@@ -0,0 +1,106 @@
+package memtransaction
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+)
+
+// Manager implements the transaction.Manager interface for in-memory stores
+//
+// Transactions are atomic: rolling back reverts every write recorded with RecordUndo.
+// They are not isolated from each other, so that concurrent transactions see uncommitted writes
+// (READ UNCOMMITTED); isolation levels and other TxOptions besides ReadOnly, Propagation, Retry and
+// the Transaction timeout are ignored
+// Writes fail with transaction.ErrReadOnly in read-only transactions and their savepoints
+type Manager struct {
+	*transaction.Runner
+}
+
+var _ transaction.Manager = (*Manager)(nil)
+
+// New creates a new Manager
+func New(opts ...transaction.Option) *Manager {
+	return &Manager{
+		Runner: transaction.NewRunner(driver{}, transaction.NewConfig(opts...)),
+	}
+}
+
+// driver implements transaction.Driver with in-memory transactions
+type driver struct{}
+
+// Active reports whether ctx carries a Tx
+func (driver) Active(ctx context.Context) bool {
+	_, ok := transaction.TxFromContext[*Tx](ctx)
+	return ok
+}
+
+// Begin starts a new Tx
+func (driver) Begin(ctx context.Context, opts transaction.TxOptions) (context.Context, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	return transaction.WithTx(ctx, &Tx{readOnly: opts.ReadOnly}), nil
+}
+
+// Savepoint creates a savepoint in the Tx carried by ctx
+// A savepoint is read-only if the enclosing transaction is
+func (driver) Savepoint(ctx context.Context) (context.Context, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+
+	outer, err := getTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get transaction: %w", err)
+	}
+	return transaction.WithTx(ctx, &Tx{parent: outer, readOnly: outer.readOnly}), nil
+}
+
+// Commit commits the Tx, or releases the savepoint if ctx carries one
+// Like a database connection, a transaction whose context has been canceled is rolled back instead
+func (driver) Commit(ctx context.Context) error {
+	tx, err := getTx(ctx)
+	if err != nil {
+		return fmt.Errorf("get transaction: %w", err)
+	}
+
+	if tx.parent != nil {
+		if err := tx.release(); err != nil {
+			return &transaction.CommitError{Err: fmt.Errorf("release savepoint: %w", err)}
+		}
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		_ = tx.rollback()
+		return &transaction.CommitError{Err: err}
+	}
+	if err := tx.commit(); err != nil {
+		return &transaction.CommitError{Err: err}
+	}
+	return nil
+}
+
+// Rollback aborts the Tx, or rolls back to the savepoint if ctx carries one
+func (driver) Rollback(ctx context.Context) error {
+	tx, err := getTx(ctx)
+	if err != nil {
+		return fmt.Errorf("get transaction: %w", err)
+	}
+
+	if err := tx.rollback(); err != nil {
+		return fmt.Errorf("rollback transaction: %w", err)
+	}
+	return nil
+}
+
+// getTx extracts the in-memory transaction from context
+func getTx(ctx context.Context) (*Tx, error) {
+	tx, ok := transaction.TxFromContext[*Tx](ctx)
+	if !ok {
+		return nil, transaction.ErrNoTransaction
+	}
+	return tx, nil
+}
@@ -0,0 +1,129 @@
+package memtransaction
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/transactiontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keySet is a minimal transactional store
+type keySet struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func (s *keySet) insert(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := RecordUndo(ctx, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.keys, key)
+	}); err != nil {
+		return err
+	}
+
+	s.keys[key] = true
+	return nil
+}
+
+func (s *keySet) exists(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.keys[key], nil
+}
+
+func TestManagerSuite(t *testing.T) {
+	transactiontest.RunManagerSuite(t, func(t *testing.T) transactiontest.Harness {
+		keys := &keySet{keys: make(map[string]bool)}
+
+		return transactiontest.Harness{
+			Manager: New(),
+			Insert:  keys.insert,
+			Exists:  keys.exists,
+		}
+	})
+}
+
+// TestChainSuite checks that transaction.Chain keeps the behavior of the Manager it wraps,
+// manual transactions included
+func TestChainSuite(t *testing.T) {
+	passThrough := func(next transaction.ExecFunc) transaction.ExecFunc {
+		return func(ctx context.Context, opts transaction.TxOptions, fn func(ctx context.Context) error) error {
+			return next(ctx, opts, fn)
+		}
+	}
+
+	transactiontest.RunManagerSuite(t, func(t *testing.T) transactiontest.Harness {
+		keys := &keySet{keys: make(map[string]bool)}
+
+		return transactiontest.Harness{
+			Manager: transaction.Chain(New(), passThrough, passThrough),
+			Insert:  keys.insert,
+			Exists:  keys.exists,
+		}
+	})
+}
+
+func TestReadOnly(t *testing.T) {
+	tests := map[string]struct {
+		run           func(m *Manager, write func(ctx context.Context) error) error
+		expectedError error
+	}{
+		"error - write in ExecReadOnly": {
+			run: func(m *Manager, write func(ctx context.Context) error) error {
+				return m.ExecReadOnly(context.Background(), write)
+			},
+			expectedError: transaction.ErrReadOnly,
+		},
+		"error - write in a savepoint of a read-only transaction": {
+			run: func(m *Manager, write func(ctx context.Context) error) error {
+				return m.ExecReadOnly(context.Background(), func(ctx context.Context) error {
+					return m.ExecTx(ctx, write)
+				})
+			},
+			expectedError: transaction.ErrReadOnly,
+		},
+		"error - write in a transaction begun read-only": {
+			run: func(m *Manager, write func(ctx context.Context) error) error {
+				ctx, err := m.BeginWithOptions(context.Background(), transaction.TxOptions{ReadOnly: true})
+				if err != nil {
+					return err
+				}
+				defer func() { _ = m.Rollback(ctx) }()
+				return write(ctx)
+			},
+			expectedError: transaction.ErrReadOnly,
+		},
+		"success - ExecReadOnly joins a read-write transaction": {
+			run: func(m *Manager, write func(ctx context.Context) error) error {
+				return m.ExecTx(context.Background(), func(ctx context.Context) error {
+					return m.ExecReadOnly(ctx, write)
+				})
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			keys := &keySet{keys: make(map[string]bool)}
+			write := func(ctx context.Context) error {
+				return keys.insert(ctx, "a")
+			}
+
+			err := tt.run(New(), write)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			exists, err := keys.exists(context.Background(), "a")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError == nil, exists)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Package memtransaction implements transaction.Manager for in-memory stores
+//
+// Stores make their writes transactional by registering how to revert each of them with RecordUndo;
+// rolling back a transaction, or a savepoint, replays the undo log in reverse order
+package memtransaction
+
+import (
+	"context"
+	"sync"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+)
+
+// Tx is an in-memory transaction, or a savepoint in one
+// It keeps an undo log of the writes made through it, which is replayed in reverse order on rollback
+type Tx struct {
+	mu       sync.Mutex
+	parent   *Tx
+	undo     []func()
+	done     bool
+	readOnly bool
+}
+
+// RecordUndo registers undo to revert a write made in the transaction carried by ctx
+// Stores call it right before applying the write; undo must take the locks it needs itself
+// Outside of a transaction writes are final, and undo is dropped
+// It returns transaction.ErrReadOnly in a read-only transaction, so that the store does not apply the write,
+// and an error wrapping transaction.ErrTxDone if the transaction has already been completed
+func RecordUndo(ctx context.Context, undo func()) error {
+	tx, ok := transaction.TxFromContext[*Tx](ctx)
+	if !ok {
+		return nil
+	}
+
+	if tx.readOnly {
+		return transaction.ErrReadOnly
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return transaction.ErrTxDone
+	}
+	tx.undo = append(tx.undo, undo)
+	return nil
+}
+
+// release completes a savepoint and hands its undo log over to the enclosing transaction
+func (tx *Tx) release() error {
+	undo, err := tx.finish()
+	if err != nil {
+		return err
+	}
+
+	tx.parent.mu.Lock()
+	defer tx.parent.mu.Unlock()
+
+	if tx.parent.done {
+		return transaction.ErrTxDone
+	}
+	tx.parent.undo = append(tx.parent.undo, undo...)
+	return nil
+}
+
+// commit completes a transaction, making its writes final
+func (tx *Tx) commit() error {
+	_, err := tx.finish()
+	return err
+}
+
+// rollback completes the transaction or savepoint and reverts its writes, newest first
+func (tx *Tx) rollback() error {
+	undo, err := tx.finish()
+	if err != nil {
+		return err
+	}
+
+	for i := len(undo) - 1; i >= 0; i-- {
+		undo[i]()
+	}
+	return nil
+}
+
+// finish marks the transaction as completed and returns its undo log
+func (tx *Tx) finish() ([]func(), error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return nil, transaction.ErrTxDone
+	}
+
+	undo := tx.undo
+	tx.done, tx.undo = true, nil
+	return undo, nil
+}
@@ -0,0 +1,230 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// errNotBegun is returned by Begin when a middleware returned without calling next or fn
+var errNotBegun = errors.New("middleware returned without beginning the transaction")
+
+// errBegunTwice is returned to a middleware calling next more than once for a transaction started by Begin
+var errBegunTwice = errors.New("transaction started by Begin cannot be run again")
+
+// ExecFunc runs fn in a unit of work started with opts, like Manager.ExecTxWithOptions
+type ExecFunc func(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error
+
+// Middleware wraps the ExecFunc of a Manager to run code around its units of work
+// A middleware may change ctx and opts before calling next, and inspect or wrap the error next returns
+type Middleware func(next ExecFunc) ExecFunc
+
+// Chain returns a Manager running every unit of work of m through mws
+//
+// The first middleware is the outermost: with Chain(m, a, b), a calls b, which calls m, and the error
+// returned by m goes back through b, then a
+// ExecTx and ExecReadOnly run the chain with TxOptions{} and ReadOnlyTxOptions(), ExecTxWithOptions with the given options
+// A transaction started by Begin runs the chain until Commit or Rollback: fn, standing for the work done
+// between them, is called once the transaction has begun, and next returns once the transaction has been
+// committed, with nil or the commit error, or rolled back, with ErrRolledBack
+// If the context given to Begin is canceled first, Commit rolls the transaction back instead, and next returns
+// ErrTxDone with the context error; the transaction is never ended while the caller may still be using it
+// A panic in the chain is raised again in the caller of Begin, Commit or Rollback
+func Chain(m Manager, mws ...Middleware) Manager {
+	if len(mws) == 0 {
+		return m
+	}
+	return &chain{next: m, mws: mws}
+}
+
+// chain is the Manager returned by Chain
+type chain struct {
+	next Manager
+	mws  []Middleware
+}
+
+// manualKey is a key for retrieving the transaction started by Begin from context
+type manualKey struct{}
+
+// wrap returns exec wrapped by the middlewares, the first one outermost
+func (c *chain) wrap(exec ExecFunc) ExecFunc {
+	for i := len(c.mws) - 1; i >= 0; i-- {
+		exec = c.mws[i](exec)
+	}
+	return exec
+}
+
+// Begin starts a new transaction through the middlewares
+func (c *chain) Begin(ctx context.Context) (context.Context, error) {
+	return c.BeginWithOptions(ctx, TxOptions{})
+}
+
+// BeginWithOptions starts a new transaction with the given options through the middlewares
+// The chain runs in its own goroutine, waiting in next for Commit or Rollback
+func (c *chain) BeginWithOptions(ctx context.Context, opts TxOptions) (context.Context, error) {
+	tx := &manualTx{
+		began: make(chan context.Context, 1),
+		end:   make(chan endRequest, 1),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(tx.done)
+		defer func() {
+			if p := recover(); p != nil {
+				tx.panicked, tx.panicValue = true, p
+			}
+		}()
+		tx.err = c.wrap(tx.exec(c.next))(ctx, opts, tx.begin)
+	}()
+
+	select {
+	case txCtx := <-tx.began:
+		return context.WithValue(txCtx, manualKey{}, tx), nil
+	case <-tx.done:
+		tx.repanic()
+		if tx.err == nil {
+			return nil, errNotBegun
+		}
+		return nil, tx.err
+	}
+}
+
+// Commit commits the transaction and returns the error of the chain
+func (c *chain) Commit(ctx context.Context) error {
+	tx, ok := ctx.Value(manualKey{}).(*manualTx)
+	if !ok || !tx.finish(ctx, true) {
+		return c.next.Commit(ctx)
+	}
+	return tx.err
+}
+
+// Rollback aborts the transaction and returns the error of the rollback
+func (c *chain) Rollback(ctx context.Context) error {
+	tx, ok := ctx.Value(manualKey{}).(*manualTx)
+	if !ok || !tx.finish(ctx, false) {
+		return c.next.Rollback(ctx)
+	}
+	return tx.endErr
+}
+
+// ExecTx executes a function within a transaction through the middlewares
+func (c *chain) ExecTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return c.ExecTxWithOptions(ctx, TxOptions{}, fn)
+}
+
+// ExecTxWithOptions executes a function within a transaction started with the given options through the middlewares
+func (c *chain) ExecTxWithOptions(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	return c.wrap(c.next.ExecTxWithOptions)(ctx, opts, fn)
+}
+
+// ExecReadOnly executes a function within a read-only transaction through the middlewares
+func (c *chain) ExecReadOnly(ctx context.Context, fn func(ctx context.Context) error) error {
+	return c.ExecTxWithOptions(ctx, ReadOnlyTxOptions(), fn)
+}
+
+// manualTx bridges a transaction started by Begin to the chain running it
+type manualTx struct {
+	// began receives the context of the transaction once it has begun
+	began chan context.Context
+
+	// sent is set once the context has been sent to began
+	sent bool
+
+	// end receives the request of Commit or Rollback
+	end chan endRequest
+
+	// done is closed once the chain has returned
+	done chan struct{}
+
+	// ended is set by the first Commit or Rollback
+	ended atomic.Bool
+
+	// err is the error returned by the chain
+	err error
+
+	// endErr is the error returned by the commit or rollback of the transaction
+	endErr error
+
+	// panicked is set if the chain panicked, with panicValue
+	panicked   bool
+	panicValue any
+}
+
+// endRequest asks the chain of a transaction started by Begin to end it
+type endRequest struct {
+	// commit is true to commit the transaction, false to roll it back
+	commit bool
+
+	// ctx is the context given to Commit or Rollback
+	ctx context.Context
+}
+
+// exec returns the innermost ExecFunc of the chain, beginning the transaction with m, calling fn
+// and waiting for Commit or Rollback to end it
+// The transaction is only ended on behalf of Commit or Rollback, while their caller waits: neither pgx.Tx nor sql.Tx
+// may be used by two goroutines at once, so a canceled ctx is only acted upon once the caller ends the transaction
+func (tx *manualTx) exec(m Manager) ExecFunc {
+	begun := false
+	return func(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+		if begun {
+			return errBegunTwice
+		}
+		begun = true
+
+		txCtx, err := m.BeginWithOptions(ctx, opts)
+		if err != nil {
+			return err
+		}
+		if err := fn(txCtx); err != nil || !tx.sent {
+			if err == nil {
+				err = errNotBegun
+			}
+			if rbErr := m.Rollback(txCtx); rbErr != nil {
+				return &RollbackError{Err: err, RollbackErr: rbErr}
+			}
+			return err
+		}
+
+		req := <-tx.end
+		if err := ctx.Err(); err != nil && req.commit {
+			// The transaction cannot be committed once ctx is done, the driver may even have rolled it back already
+			tx.endErr = WithRollback(fmt.Errorf("%w: %w", ErrTxDone, err), m.Rollback(req.ctx))
+			return tx.endErr
+		}
+
+		if req.commit {
+			tx.endErr = m.Commit(req.ctx)
+			return tx.endErr
+		}
+		tx.endErr = m.Rollback(req.ctx)
+		return WithRollback(ErrRolledBack, tx.endErr)
+	}
+}
+
+// begin is the fn given to the chain for a transaction started by Begin
+// It hands the context of the transaction, as seen by the middlewares, over to Begin
+func (tx *manualTx) begin(ctx context.Context) error {
+	tx.sent = true
+	tx.began <- ctx
+	return nil
+}
+
+// finish ends the transaction with the context given to Commit or Rollback and waits for the chain to return
+// It reports false if the transaction has already been ended
+func (tx *manualTx) finish(ctx context.Context, commit bool) bool {
+	if !tx.ended.CompareAndSwap(false, true) {
+		return false
+	}
+	tx.end <- endRequest{commit: commit, ctx: ctx}
+	<-tx.done
+	tx.repanic()
+	return true
+}
+
+// repanic raises the panic of the chain, if any, in the calling goroutine
+func (tx *manualTx) repanic() {
+	if tx.panicked {
+		panic(tx.panicValue)
+	}
+}
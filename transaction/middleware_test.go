@@ -0,0 +1,391 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ctxKey string
+
+// logManager records the calls it receives in log, next to the ones of the middlewares
+type logManager struct {
+	log       *[]string
+	opts      TxOptions
+	commitErr error
+
+	// endCtx is the context given to the last Commit or Rollback
+	endCtx context.Context
+}
+
+func (m *logManager) Begin(ctx context.Context) (context.Context, error) {
+	return m.BeginWithOptions(ctx, TxOptions{})
+}
+
+func (m *logManager) BeginWithOptions(ctx context.Context, opts TxOptions) (context.Context, error) {
+	*m.log = append(*m.log, "Begin")
+	m.opts = opts
+	return context.WithValue(ctx, ctxKey("tx"), "tx"), nil
+}
+
+func (m *logManager) Commit(ctx context.Context) error {
+	*m.log = append(*m.log, "Commit")
+	m.endCtx = ctx
+	return m.commitErr
+}
+
+func (m *logManager) Rollback(ctx context.Context) error {
+	*m.log = append(*m.log, "Rollback")
+	m.endCtx = ctx
+	return nil
+}
+
+func (m *logManager) ExecTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.ExecTxWithOptions(ctx, TxOptions{}, fn)
+}
+
+func (m *logManager) ExecTxWithOptions(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	*m.log = append(*m.log, "ExecTx")
+	m.opts = opts
+	if err := fn(context.WithValue(ctx, ctxKey("tx"), "tx")); err != nil {
+		return err
+	}
+	return m.commitErr
+}
+
+func (m *logManager) ExecReadOnly(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.ExecTxWithOptions(ctx, ReadOnlyTxOptions(), fn)
+}
+
+// logMiddleware records the start of a unit of work as "<name> before" and its end as "<name> after: <error>"
+// It passes a value named after it in the context
+func logMiddleware(log *[]string, name string) Middleware {
+	return func(next ExecFunc) ExecFunc {
+		return func(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+			*log = append(*log, name+" before")
+			err := next(context.WithValue(ctx, ctxKey(name), name), opts, fn)
+			*log = append(*log, name+" after: "+errString(err))
+			return err
+		}
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Error()
+}
+
+func TestChain_Exec(t *testing.T) {
+	errFn := errors.New("fn failed")
+	errCommit := errors.New("commit failed")
+
+	tests := map[string]struct {
+		exec          func(m Manager, fn func(ctx context.Context) error) error
+		fnErr         error
+		commitErr     error
+		expectedOpts  TxOptions
+		expectedLog   []string
+		expectedError error
+	}{
+		"success - ExecTx runs the middlewares in order": {
+			exec: func(m Manager, fn func(ctx context.Context) error) error {
+				return m.ExecTx(context.Background(), fn)
+			},
+			expectedLog: []string{"a before", "b before", "ExecTx", "fn", "b after: <nil>", "a after: <nil>"},
+		},
+		"success - ExecTxWithOptions passes the options": {
+			exec: func(m Manager, fn func(ctx context.Context) error) error {
+				return m.ExecTxWithOptions(context.Background(), TxOptions{Isolation: LevelSerializable}, fn)
+			},
+			expectedOpts: TxOptions{Isolation: LevelSerializable},
+			expectedLog:  []string{"a before", "b before", "ExecTx", "fn", "b after: <nil>", "a after: <nil>"},
+		},
+		"success - ExecReadOnly passes the read-only options": {
+			exec: func(m Manager, fn func(ctx context.Context) error) error {
+				return m.ExecReadOnly(context.Background(), fn)
+			},
+			expectedOpts: ReadOnlyTxOptions(),
+			expectedLog:  []string{"a before", "b before", "ExecTx", "fn", "b after: <nil>", "a after: <nil>"},
+		},
+		"error - the error of fn goes back through the middlewares": {
+			exec: func(m Manager, fn func(ctx context.Context) error) error {
+				return m.ExecTx(context.Background(), fn)
+			},
+			fnErr:         errFn,
+			expectedLog:   []string{"a before", "b before", "ExecTx", "fn", "b after: fn failed", "a after: fn failed"},
+			expectedError: errFn,
+		},
+		"error - the commit error goes back through the middlewares": {
+			exec: func(m Manager, fn func(ctx context.Context) error) error {
+				return m.ExecTx(context.Background(), fn)
+			},
+			commitErr:     errCommit,
+			expectedLog:   []string{"a before", "b before", "ExecTx", "fn", "b after: commit failed", "a after: commit failed"},
+			expectedError: errCommit,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var log []string
+			next := &logManager{log: &log, commitErr: tt.commitErr}
+			m := Chain(next, logMiddleware(&log, "a"), logMiddleware(&log, "b"))
+
+			err := tt.exec(m, func(ctx context.Context) error {
+				log = append(log, "fn")
+				// fn sees the values of the middlewares and of the transaction
+				assert.Equal(t, "a", ctx.Value(ctxKey("a")))
+				assert.Equal(t, "b", ctx.Value(ctxKey("b")))
+				assert.Equal(t, "tx", ctx.Value(ctxKey("tx")))
+				return tt.fnErr
+			})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedOpts, next.opts)
+			assert.Equal(t, tt.expectedLog, log)
+		})
+	}
+}
+
+func TestChain_Begin(t *testing.T) {
+	errCommit := errors.New("commit failed")
+
+	tests := map[string]struct {
+		end           func(ctx context.Context, m Manager) error
+		commitErr     error
+		expectedLog   []string
+		expectedError error
+	}{
+		"success - Commit": {
+			end: func(ctx context.Context, m Manager) error { return m.Commit(ctx) },
+			expectedLog: []string{
+				"a before", "b before", "Begin", "work", "Commit", "b after: <nil>", "a after: <nil>",
+			},
+		},
+		"success - Rollback is seen by the middlewares as ErrRolledBack": {
+			end: func(ctx context.Context, m Manager) error { return m.Rollback(ctx) },
+			expectedLog: []string{
+				"a before", "b before", "Begin", "work", "Rollback",
+				"b after: transaction rolled back", "a after: transaction rolled back",
+			},
+		},
+		"error - Commit returns the commit error": {
+			end:       func(ctx context.Context, m Manager) error { return m.Commit(ctx) },
+			commitErr: errCommit,
+			expectedLog: []string{
+				"a before", "b before", "Begin", "work", "Commit", "b after: commit failed", "a after: commit failed",
+			},
+			expectedError: errCommit,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var log []string
+			next := &logManager{log: &log, commitErr: tt.commitErr}
+			m := Chain(next, logMiddleware(&log, "a"), logMiddleware(&log, "b"))
+
+			ctx, err := m.BeginWithOptions(context.Background(), TxOptions{ReadOnly: true})
+			require.NoError(t, err)
+			assert.Equal(t, TxOptions{ReadOnly: true}, next.opts)
+			assert.Equal(t, "a", ctx.Value(ctxKey("a")))
+			assert.Equal(t, "b", ctx.Value(ctxKey("b")))
+			assert.Equal(t, "tx", ctx.Value(ctxKey("tx")))
+			log = append(log, "work")
+
+			err = tt.end(context.WithValue(ctx, ctxKey("end"), "end"), m)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedLog, log)
+			// The transaction is ended with the context of the caller
+			assert.Equal(t, "end", next.endCtx.Value(ctxKey("end")))
+
+			// A transaction can be ended only once; the second call reaches the Manager directly
+			_ = m.Rollback(ctx)
+			assert.Equal(t, "Rollback", log[len(log)-1])
+		})
+	}
+}
+
+func TestChain_BeginErrors(t *testing.T) {
+	errMiddleware := errors.New("middleware failed")
+
+	tests := map[string]struct {
+		mw            Middleware
+		expectedLog   []string
+		expectedError error
+	}{
+		"error - middleware returns an error without calling next": {
+			mw: func(next ExecFunc) ExecFunc {
+				return func(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+					return errMiddleware
+				}
+			},
+			expectedLog:   nil,
+			expectedError: errMiddleware,
+		},
+		"error - middleware returns nil without calling next": {
+			mw: func(next ExecFunc) ExecFunc {
+				return func(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+					return nil
+				}
+			},
+			expectedLog:   nil,
+			expectedError: errNotBegun,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var log []string
+			m := Chain(&logManager{log: &log}, tt.mw)
+
+			ctx, err := m.Begin(context.Background())
+
+			assert.Nil(t, ctx)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedLog, log)
+		})
+	}
+}
+
+func TestChain_BeginPanic(t *testing.T) {
+	tests := map[string]struct {
+		mw          Middleware
+		end         func(ctx context.Context, m Manager) error
+		expectedLog []string
+	}{
+		"middleware panics before beginning": {
+			mw: func(next ExecFunc) ExecFunc {
+				return func(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+					panic("boom")
+				}
+			},
+			expectedLog: nil,
+		},
+		"middleware panics after the commit": {
+			mw: func(next ExecFunc) ExecFunc {
+				return func(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+					_ = next(ctx, opts, fn)
+					panic("boom")
+				}
+			},
+			end:         func(ctx context.Context, m Manager) error { return m.Commit(ctx) },
+			expectedLog: []string{"Begin", "Commit"},
+		},
+		"middleware panics after the rollback": {
+			mw: func(next ExecFunc) ExecFunc {
+				return func(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+					_ = next(ctx, opts, fn)
+					panic("boom")
+				}
+			},
+			end:         func(ctx context.Context, m Manager) error { return m.Rollback(ctx) },
+			expectedLog: []string{"Begin", "Rollback"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var log []string
+			m := Chain(&logManager{log: &log}, tt.mw)
+
+			// The panic is raised in the caller rather than in the goroutine running the chain
+			assert.PanicsWithValue(t, "boom", func() {
+				ctx, err := m.Begin(context.Background())
+				require.NoError(t, err)
+				_ = tt.end(ctx, m)
+			})
+			assert.Equal(t, tt.expectedLog, log)
+		})
+	}
+}
+
+func TestChain_BeginCanceled(t *testing.T) {
+	tests := map[string]struct {
+		end         func(ctx context.Context, m Manager) error
+		expectedErr []error
+		expectedLog []string
+	}{
+		"commit rolls back and reports the cancellation": {
+			end:         func(ctx context.Context, m Manager) error { return m.Commit(ctx) },
+			expectedErr: []error{ErrTxDone, context.Canceled},
+			expectedLog: []string{
+				"a before", "Begin", "Rollback",
+				"a after: transaction has already been committed or rolled back: context canceled",
+			},
+		},
+		"rollback": {
+			end:         func(ctx context.Context, m Manager) error { return m.Rollback(ctx) },
+			expectedLog: []string{"a before", "Begin", "Rollback", "a after: transaction rolled back"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var log []string
+			m := Chain(&logManager{log: &log}, logMiddleware(&log, "a"))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			txCtx, err := m.Begin(ctx)
+			require.NoError(t, err)
+
+			// The transaction is left alone until it is ended, which rolls it back
+			cancel()
+			err = tt.end(txCtx, m)
+
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+			}
+			for _, expected := range tt.expectedErr {
+				assert.ErrorIs(t, err, expected)
+			}
+			assert.Equal(t, tt.expectedLog, log)
+		})
+	}
+}
+
+func TestChain_CanceledDuringStatement(t *testing.T) {
+	var log []string
+	m := Chain(&logManager{log: &log}, logMiddleware(&log, "a"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	txCtx, err := m.Begin(ctx)
+	require.NoError(t, err)
+
+	// The statements use the transaction without synchronization, like pgx.Tx and sql.Tx:
+	// under -race, a rollback run by the chain as soon as ctx is canceled would be reported as a data race
+	log = append(log, "statement")
+	cancel()
+	for range 3 {
+		time.Sleep(time.Millisecond)
+		log = append(log, "statement")
+	}
+
+	err = m.Commit(txCtx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{
+		"a before", "Begin", "statement", "statement", "statement", "statement", "Rollback",
+		"a after: transaction has already been committed or rolled back: context canceled",
+	}, log)
+}
+
+func TestChain_NoMiddleware(t *testing.T) {
+	next := &logManager{log: &[]string{}}
+	assert.Same(t, next, Chain(next))
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	transaction "github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -55,6 +56,21 @@ func (mr *MockManagerMockRecorder) Begin(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockManager)(nil).Begin), ctx)
 }
 
+// BeginWithOptions mocks base method.
+func (m *MockManager) BeginWithOptions(ctx context.Context, opts transaction.TxOptions) (context.Context, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginWithOptions", ctx, opts)
+	ret0, _ := ret[0].(context.Context)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginWithOptions indicates an expected call of BeginWithOptions.
+func (mr *MockManagerMockRecorder) BeginWithOptions(ctx, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginWithOptions", reflect.TypeOf((*MockManager)(nil).BeginWithOptions), ctx, opts)
+}
+
 // Commit mocks base method.
 func (m *MockManager) Commit(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -69,6 +85,20 @@ func (mr *MockManagerMockRecorder) Commit(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockManager)(nil).Commit), ctx)
 }
 
+// ExecReadOnly mocks base method.
+func (m *MockManager) ExecReadOnly(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecReadOnly", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecReadOnly indicates an expected call of ExecReadOnly.
+func (mr *MockManagerMockRecorder) ExecReadOnly(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecReadOnly", reflect.TypeOf((*MockManager)(nil).ExecReadOnly), ctx, fn)
+}
+
 // ExecTx mocks base method.
 func (m *MockManager) ExecTx(ctx context.Context, fn func(context.Context) error) error {
 	m.ctrl.T.Helper()
@@ -83,6 +113,20 @@ func (mr *MockManagerMockRecorder) ExecTx(ctx, fn any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecTx", reflect.TypeOf((*MockManager)(nil).ExecTx), ctx, fn)
 }
 
+// ExecTxWithOptions mocks base method.
+func (m *MockManager) ExecTxWithOptions(ctx context.Context, opts transaction.TxOptions, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecTxWithOptions", ctx, opts, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecTxWithOptions indicates an expected call of ExecTxWithOptions.
+func (mr *MockManagerMockRecorder) ExecTxWithOptions(ctx, opts, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecTxWithOptions", reflect.TypeOf((*MockManager)(nil).ExecTxWithOptions), ctx, opts, fn)
+}
+
 // Rollback mocks base method.
 func (m *MockManager) Rollback(ctx context.Context) error {
 	m.ctrl.T.Helper()
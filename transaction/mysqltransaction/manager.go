@@ -0,0 +1,79 @@
+// Package mysqltransaction implements transaction.Manager for MySQL on top of sqltransaction
+package mysqltransaction
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/sqltransaction"
+	"github.com/go-sql-driver/mysql"
+)
+
+// Server error numbers of the errors that are worth retrying in a fresh transaction
+const (
+	erLockWaitTimeout = 1205
+	erLockDeadlock    = 1213
+)
+
+// Open opens the MySQL database described by dsn (e.g. "user:password@tcp(localhost:3306)/app")
+// with the settings the stores rely on:
+//   - DATETIME columns are scanned into time.Time
+//   - times are read and written in UTC unless dsn sets another loc
+func Open(dsn string) (*sql.DB, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ParseTime = true
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// Manager implements the transaction.Manager interface for MySQL
+//
+// It behaves like sqltransaction.Manager, with the following differences:
+//   - the isolation levels are set with SET TRANSACTION ISOLATION LEVEL, LevelDefault keeping
+//     the one of the server (REPEATABLE READ unless configured otherwise)
+//   - TxOptions.Deferrable is ignored, MySQL having no deferrable transactions
+//   - only the Transaction timeout applies, MySQL having no transaction-scoped statement or lock timeout
+//   - unless a retry policy sets its own Retryable function, deadlocks (error 1213)
+//     and lock wait timeouts (error 1205) are retried
+type Manager struct {
+	*sqltransaction.Manager
+}
+
+var _ transaction.Manager = (*Manager)(nil)
+
+// dialect is how MySQL departs from the PostgreSQL behavior of sqltransaction.Manager
+var dialect = sqltransaction.Dialect{
+	IsRetryable:  IsRetryable,
+	DeadlineOnly: true,
+	NoDeferrable: true,
+}
+
+// New creates a new Manager with the provided database connection, which should be opened with Open
+func New(db *sql.DB, opts ...transaction.Option) *Manager {
+	return &Manager{
+		Manager: sqltransaction.NewWithDialect(db, dialect, opts...),
+	}
+}
+
+// IsRetryable reports whether err is a deadlock (error 1213) or a lock wait timeout (error 1205),
+// or is a serialization failure or deadlock according to transaction.IsRetryable
+// MySQL rolls back the whole transaction on a deadlock, but only the failed statement on a lock wait timeout:
+// retrying is left to ExecTx, which rolls back the transaction before running it again
+func IsRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case erLockDeadlock, erLockWaitTimeout:
+			return true
+		}
+	}
+	return transaction.IsRetryable(err)
+}
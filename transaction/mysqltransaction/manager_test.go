@@ -0,0 +1,152 @@
+package mysqltransaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/mysqltest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/transactiontest"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDB returns a database of mysqltest.NewEmptyDB with an items table
+func newDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db := mysqltest.NewEmptyDB(t)
+	_, err := db.Exec("CREATE TABLE items (name VARCHAR(255) NOT NULL)")
+	require.NoError(t, err)
+	return db
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func TestManagerSuite(t *testing.T) {
+	transactiontest.RunManagerSuite(t, func(t *testing.T) transactiontest.Harness {
+		mysqltest.SkipInMemory(t, "does not support savepoints",
+			"ManualTransaction/nested_Begin", "Nesting/", "Propagation/nested_-_savepoint")
+		db := newDB(t)
+
+		return transactiontest.Harness{
+			Manager: New(db),
+			Insert: func(ctx context.Context, key string) error {
+				_, err := transaction.Executor[execer](ctx, db).ExecContext(ctx, "INSERT INTO items (name) VALUES (?)", key)
+				return err
+			},
+			Exists: func(ctx context.Context, key string) (bool, error) {
+				var exists bool
+				err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM items WHERE name = ?)", key).Scan(&exists)
+				return exists, err
+			},
+		}
+	})
+}
+
+func TestExecTxWithOptions_MySQL(t *testing.T) {
+	tests := map[string]struct {
+		opts            transaction.TxOptions
+		expectedVisible bool
+	}{
+		"default - repeatable read does not see rows committed meanwhile": {
+			opts:            transaction.TxOptions{},
+			expectedVisible: false,
+		},
+		"read committed - sees rows committed meanwhile": {
+			opts:            transaction.TxOptions{Isolation: transaction.LevelReadCommitted},
+			expectedVisible: true,
+		},
+		"read-only deferrable - deferrable is ignored": {
+			opts:            transaction.TxOptions{Isolation: transaction.LevelRepeatableRead, ReadOnly: true, Deferrable: true},
+			expectedVisible: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mysqltest.SkipInMemory(t, "runs every transaction in REPEATABLE READ", "read_committed")
+			db := newDB(t)
+			m := New(db)
+
+			var visible bool
+			err := m.ExecTxWithOptions(context.Background(), tt.opts, func(ctx context.Context) error {
+				tx, _ := transaction.TxFromContext[*sql.Tx](ctx)
+
+				// The first read takes the snapshot of REPEATABLE READ and SERIALIZABLE transactions
+				var n int
+				if err := tx.QueryRowContext(ctx, "SELECT count(*) FROM items").Scan(&n); err != nil {
+					return err
+				}
+				if _, err := db.ExecContext(context.Background(), "INSERT INTO items (name) VALUES ('a')"); err != nil {
+					return err
+				}
+				if err := tx.QueryRowContext(ctx, "SELECT count(*) FROM items").Scan(&n); err != nil {
+					return err
+				}
+
+				visible = n > 0
+				return nil
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedVisible, visible)
+		})
+	}
+}
+
+func TestNew_Timeouts(t *testing.T) {
+	db := newDB(t)
+	// The PostgreSQL settings are dropped instead of failing the transaction
+	m := New(db, transaction.WithTimeouts(transaction.Timeouts{Statement: time.Second, Lock: time.Second}))
+
+	assert.NoError(t, m.ExecTx(context.Background(), func(ctx context.Context) error { return nil }))
+
+	ctx, err := m.BeginWithOptions(context.Background(), transaction.TxOptions{
+		Timeouts: &transaction.Timeouts{IdleInTransaction: time.Second},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, m.Commit(ctx))
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"deadlock": {
+			err:      &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock; try restarting transaction"},
+			expected: true,
+		},
+		"lock wait timeout": {
+			err:      &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded; try restarting transaction"},
+			expected: true,
+		},
+		"serialization failure": {
+			err:      &pgconn.PgError{Code: "40001"},
+			expected: true,
+		},
+		"duplicate entry": {
+			err:      &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'items.PRIMARY'"},
+			expected: false,
+		},
+		"other error": {
+			err:      errors.New("connection refused"),
+			expected: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRetryable(tt.err))
+		})
+	}
+}
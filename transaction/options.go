@@ -0,0 +1,64 @@
+package transaction
+
+// IsolationLevel is the isolation level of a transaction
+type IsolationLevel int
+
+const (
+	// LevelDefault uses the default isolation level of the database
+	LevelDefault IsolationLevel = iota
+	LevelReadUncommitted
+	LevelReadCommitted
+	LevelRepeatableRead
+	LevelSerializable
+)
+
+// String returns the SQL name of the isolation level
+func (l IsolationLevel) String() string {
+	switch l {
+	case LevelReadUncommitted:
+		return "READ UNCOMMITTED"
+	case LevelReadCommitted:
+		return "READ COMMITTED"
+	case LevelRepeatableRead:
+		return "REPEATABLE READ"
+	case LevelSerializable:
+		return "SERIALIZABLE"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// TxOptions holds the driver-neutral options used to start a transaction
+// The zero value starts a read-write transaction with the default isolation level
+type TxOptions struct {
+	// Isolation is the isolation level of the transaction
+	Isolation IsolationLevel
+
+	// ReadOnly starts a READ ONLY transaction
+	ReadOnly bool
+
+	// Deferrable starts a DEFERRABLE transaction (PostgreSQL only)
+	// It only has an effect for SERIALIZABLE READ ONLY transactions
+	Deferrable bool
+
+	// Propagation defines how the transaction relates to one already carried by the context
+	// Options other than Propagation are ignored unless a new transaction is started
+	Propagation Propagation
+
+	// Retry overrides the retry policy of the Manager for this transaction, if not nil
+	Retry *RetryPolicy
+
+	// Timeouts overrides the timeouts of the Manager for this transaction, if not nil
+	Timeouts *Timeouts
+}
+
+// ReadOnlyTxOptions returns the options of Manager.ExecReadOnly: a REPEATABLE READ READ ONLY transaction,
+// so that several reads see the same snapshot of the database
+// The function joins the transaction carried by the context, if any, instead of starting a new one
+func ReadOnlyTxOptions() TxOptions {
+	return TxOptions{
+		Isolation:   LevelRepeatableRead,
+		ReadOnly:    true,
+		Propagation: PropagationRequired,
+	}
+}
@@ -0,0 +1,36 @@
+package transaction
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned by ExecTx instead of re-panicking when the Manager is configured with WithPanicAsError
+type PanicError struct {
+	// Value is the value the function panicked with
+	Value any
+
+	// Stack is the stack trace of the goroutine at the time of the panic
+	Stack []byte
+}
+
+// NewPanicError returns a PanicError for the recovered value v, capturing the current stack
+// It is meant to be called from the deferred function that recovered the panic
+func NewPanicError(v any) *PanicError {
+	return &PanicError{
+		Value: v,
+		Stack: debug.Stack(),
+	}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in transaction: %v", e.Value)
+}
+
+// Unwrap returns the value the function panicked with if it is an error
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
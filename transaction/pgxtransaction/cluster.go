@@ -0,0 +1,187 @@
+package pgxtransaction
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// node is a connection pool of a Cluster, implemented by *pgxpool.Pool
+type node interface {
+	beginner
+	Executor
+}
+
+// Balancer chooses the replica serving the next read
+type Balancer interface {
+	// Pick returns the index of a replica among n
+	// conns(i) is the number of connections currently in use in the i-th replica
+	Pick(n int, conns func(i int) int) int
+}
+
+// RoundRobin returns a Balancer using the replicas in turn
+func RoundRobin() Balancer {
+	return &roundRobin{}
+}
+
+type roundRobin struct {
+	next atomic.Uint64
+}
+
+func (b *roundRobin) Pick(n int, _ func(i int) int) int {
+	return int((b.next.Add(1) - 1) % uint64(n))
+}
+
+// LeastConnections returns a Balancer using the replica with the fewest connections in use,
+// the first one in case of a tie
+func LeastConnections() Balancer {
+	return leastConnections{}
+}
+
+type leastConnections struct{}
+
+func (leastConnections) Pick(n int, conns func(i int) int) int {
+	best, bestConns := 0, conns(0)
+	for i := 1; i < n; i++ {
+		if c := conns(i); c < bestConns {
+			best, bestConns = i, c
+		}
+	}
+	return best
+}
+
+// Cluster routes queries between a primary pool and its read replicas
+//
+// It implements Executor, so it can be given to the stores in place of a pool:
+//   - Query and QueryRow run SELECT statements without a locking clause on a replica
+//   - every other statement runs on the primary
+//
+// These rules only apply without a transaction: inside one, the stores use its pgx.Tx,
+// which NewWithCluster starts on a replica for read-only transactions and on the primary otherwise
+type Cluster struct {
+	primary  node
+	replicas []node
+	conns    func(i int) int
+	balancer Balancer
+}
+
+var _ Executor = (*Cluster)(nil)
+
+// ClusterOption configures a Cluster
+type ClusterOption func(*Cluster)
+
+// WithBalancer sets how replicas are chosen, RoundRobin by default
+func WithBalancer(balancer Balancer) ClusterOption {
+	return func(c *Cluster) {
+		c.balancer = balancer
+	}
+}
+
+// NewCluster creates a Cluster writing to primary and reading from replicas
+// Without replicas, everything runs on the primary
+func NewCluster(primary *pgxpool.Pool, replicas []*pgxpool.Pool, opts ...ClusterOption) *Cluster {
+	c := &Cluster{
+		primary:  primary,
+		balancer: RoundRobin(),
+		conns: func(i int) int {
+			return int(replicas[i].Stat().AcquiredConns())
+		},
+	}
+	for _, replica := range replicas {
+		c.replicas = append(c.replicas, replica)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Exec runs sql on the primary
+func (c *Cluster) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return c.primary.Exec(ctx, sql, arguments...)
+}
+
+// Query runs sql on a replica if it is a read, on the primary otherwise
+func (c *Cluster) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return c.route(ctx, sql).Query(ctx, sql, args...)
+}
+
+// QueryRow runs sql on a replica if it is a read, on the primary otherwise
+func (c *Cluster) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return c.route(ctx, sql).QueryRow(ctx, sql, args...)
+}
+
+// route returns the node sql runs on
+func (c *Cluster) route(ctx context.Context, sql string) node {
+	if usePrimary(ctx) || !isRead(sql) {
+		return c.primary
+	}
+	return c.replica()
+}
+
+// replica returns the replica chosen by the balancer, or the primary if there is none
+func (c *Cluster) replica() node {
+	if len(c.replicas) == 0 {
+		return c.primary
+	}
+	return c.replicas[c.balancer.Pick(len(c.replicas), c.conns)]
+}
+
+type primaryKey struct{}
+
+// WithPrimary returns a context whose reads run on the primary, e.g. to read rows that were just written
+// and may not have reached the replicas yet
+// It covers the queries run by a Cluster and the read-only transactions started by Manager
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// usePrimary reports whether ctx was returned by WithPrimary
+func usePrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+var (
+	selectKeyword = regexp.MustCompile(`(?i)^select\b`)
+	lockingClause = regexp.MustCompile(`(?i)\bfor\s+(update|no\s+key\s+update|share|key\s+share)\b`)
+)
+
+// isRead reports whether sql is a SELECT statement without a locking clause
+// A SELECT calling a function that writes is not detected: such queries need WithPrimary
+func isRead(sql string) bool {
+	sql = skipComments(sql)
+	return selectKeyword.MatchString(sql) && !lockingClause.MatchString(sql)
+}
+
+// skipComments removes the spaces and comments before the first keyword of sql,
+// such as the "-- name: GetUser :one" line of the queries generated by sqlc
+func skipComments(sql string) string {
+	for {
+		sql = strings.TrimLeftFunc(sql, unicode.IsSpace)
+
+		var end int
+		switch {
+		case strings.HasPrefix(sql, "--"):
+			end = strings.IndexByte(sql, '\n')
+		case strings.HasPrefix(sql, "/*"):
+			end = strings.Index(sql, "*/")
+			if end >= 0 {
+				end++
+			}
+		default:
+			return sql
+		}
+
+		if end < 0 {
+			return ""
+		}
+		sql = sql[end+1:]
+	}
+}
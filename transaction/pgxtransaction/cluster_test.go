@@ -0,0 +1,232 @@
+package pgxtransaction
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/db"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// A Cluster can be passed to the queries generated by sqlc
+var _ db.DBTX = (*Cluster)(nil)
+
+// fakeNode is a fakePool that also records the statements it runs
+type fakeNode struct {
+	fakePool
+	name string
+	ran  *[]string
+}
+
+func (n *fakeNode) Exec(_ context.Context, _ string, _ ...any) (pgconn.CommandTag, error) {
+	*n.ran = append(*n.ran, n.name)
+	return pgconn.CommandTag{}, nil
+}
+
+func (n *fakeNode) Query(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+	*n.ran = append(*n.ran, n.name)
+	return nil, nil
+}
+
+func (n *fakeNode) QueryRow(_ context.Context, _ string, _ ...any) pgx.Row {
+	*n.ran = append(*n.ran, n.name)
+	return nil
+}
+
+// newFakeCluster returns a Cluster of fake nodes named "primary", "replica0", "replica1"...,
+// all recording the statements they run in ran
+func newFakeCluster(replicas int, ran *[]string) *Cluster {
+	c := &Cluster{
+		primary:  &fakeNode{name: "primary", ran: ran},
+		balancer: RoundRobin(),
+	}
+	for i := 0; i < replicas; i++ {
+		c.replicas = append(c.replicas, &fakeNode{name: fmt.Sprintf("replica%d", i), ran: ran})
+	}
+	return c
+}
+
+func TestIsRead(t *testing.T) {
+	tests := map[string]struct {
+		sql      string
+		expected bool
+	}{
+		"select":                           {sql: "SELECT 1", expected: true},
+		"lowercase select":                 {sql: "select 1", expected: true},
+		"select after sqlc name comment":   {sql: "-- name: GetUser :one\nSELECT id FROM users WHERE id = $1", expected: true},
+		"select after block comment":       {sql: "/* report */ SELECT count(*) FROM posts", expected: true},
+		"select for update":                {sql: "SELECT id FROM users FOR UPDATE", expected: false},
+		"select for no key update":         {sql: "SELECT id FROM users FOR NO KEY UPDATE", expected: false},
+		"select for share":                 {sql: "SELECT id FROM users\nFOR SHARE", expected: false},
+		"insert returning":                 {sql: "-- name: CreateUser :one\nINSERT INTO users (name) VALUES ($1) RETURNING id", expected: false},
+		"update":                           {sql: "UPDATE users SET name = $1", expected: false},
+		"data-modifying cte":               {sql: "WITH d AS (DELETE FROM posts RETURNING id) SELECT count(*) FROM d", expected: false},
+		"identifier starting with select":  {sql: "selected_rows()", expected: false},
+		"unterminated comment":             {sql: "/* SELECT 1", expected: false},
+		"comment only":                     {sql: "-- SELECT 1", expected: false},
+		"column named for in a projection": {sql: "SELECT \"for\" FROM t", expected: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isRead(tt.sql))
+		})
+	}
+}
+
+func TestCluster_Route(t *testing.T) {
+	tests := map[string]struct {
+		replicas int
+		primary  bool
+		run      func(ctx context.Context, c *Cluster)
+		expected []string
+	}{
+		"reads are spread over the replicas": {
+			replicas: 2,
+			run: func(ctx context.Context, c *Cluster) {
+				_, _ = c.Query(ctx, "SELECT 1")
+				_ = c.QueryRow(ctx, "SELECT 1")
+				_, _ = c.Query(ctx, "SELECT 1")
+			},
+			expected: []string{"replica0", "replica1", "replica0"},
+		},
+		"writes run on the primary": {
+			replicas: 2,
+			run: func(ctx context.Context, c *Cluster) {
+				_, _ = c.Exec(ctx, "SELECT pg_advisory_lock(1)")
+				_ = c.QueryRow(ctx, "INSERT INTO users (name) VALUES ($1) RETURNING id")
+				_, _ = c.Query(ctx, "SELECT id FROM users FOR UPDATE")
+			},
+			expected: []string{"primary", "primary", "primary"},
+		},
+		"reads run on the primary without replicas": {
+			run: func(ctx context.Context, c *Cluster) {
+				_, _ = c.Query(ctx, "SELECT 1")
+			},
+			expected: []string{"primary"},
+		},
+		"reads run on the primary with WithPrimary": {
+			replicas: 2,
+			primary:  true,
+			run: func(ctx context.Context, c *Cluster) {
+				_ = c.QueryRow(ctx, "SELECT 1")
+			},
+			expected: []string{"primary"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var ran []string
+			c := newFakeCluster(tt.replicas, &ran)
+			ctx := context.Background()
+			if tt.primary {
+				ctx = WithPrimary(ctx)
+			}
+
+			tt.run(ctx, c)
+
+			assert.Equal(t, tt.expected, ran)
+		})
+	}
+}
+
+func TestBalancers(t *testing.T) {
+	conns := []int{3, 1, 1, 2}
+
+	tests := map[string]struct {
+		balancer Balancer
+		expected []int
+	}{
+		"round robin - uses the replicas in turn": {
+			balancer: RoundRobin(),
+			expected: []int{0, 1, 2, 3, 0},
+		},
+		"least connections - uses the first of the least busy replicas": {
+			balancer: LeastConnections(),
+			expected: []int{1, 1, 1, 1, 1},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var picked []int
+			for range tt.expected {
+				picked = append(picked, tt.balancer.Pick(len(conns), func(i int) int { return conns[i] }))
+			}
+
+			assert.Equal(t, tt.expected, picked)
+		})
+	}
+}
+
+func TestNewWithCluster_Routing(t *testing.T) {
+	tests := map[string]struct {
+		primary  bool
+		run      func(ctx context.Context, m *Manager) error
+		expected []string
+	}{
+		"read-only transactions run on a replica": {
+			run: func(ctx context.Context, m *Manager) error {
+				return m.ExecReadOnly(ctx, func(ctx context.Context) error { return nil })
+			},
+			expected: []string{"replica0"},
+		},
+		"read-write transactions run on the primary": {
+			run: func(ctx context.Context, m *Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error { return nil })
+			},
+			expected: []string{"primary"},
+		},
+		"read-only transactions join the current read-write transaction": {
+			run: func(ctx context.Context, m *Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error {
+					return m.ExecReadOnly(ctx, func(ctx context.Context) error { return nil })
+				})
+			},
+			expected: []string{"primary"},
+		},
+		"serializable read-only transactions run on the primary": {
+			run: func(ctx context.Context, m *Manager) error {
+				opts := transaction.TxOptions{Isolation: transaction.LevelSerializable, ReadOnly: true}
+				return m.ExecTxWithOptions(ctx, opts, func(ctx context.Context) error { return nil })
+			},
+			expected: []string{"primary"},
+		},
+		"read-only transactions run on the primary with WithPrimary": {
+			primary: true,
+			run: func(ctx context.Context, m *Manager) error {
+				return m.ExecReadOnly(ctx, func(ctx context.Context) error { return nil })
+			},
+			expected: []string{"primary"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var ran []string
+			c := newFakeCluster(1, &ran)
+			m := NewWithCluster(c)
+			ctx := context.Background()
+			if tt.primary {
+				ctx = WithPrimary(ctx)
+			}
+
+			err := tt.run(ctx, m)
+
+			assert.NoError(t, err)
+			var begun []string
+			for _, n := range append([]node{c.primary}, c.replicas...) {
+				fake := n.(*fakeNode)
+				for range fake.begins {
+					begun = append(begun, fake.name)
+				}
+			}
+			assert.Equal(t, tt.expected, begun)
+		})
+	}
+}
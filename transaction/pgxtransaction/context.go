@@ -0,0 +1,35 @@
+package pgxtransaction
+
+import (
+	"context"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Executor is implemented by *pgxpool.Pool, *pgx.Conn and pgx.Tx
+// It has the methods of the DBTX interface generated by sqlc for pgx/v5,
+// so that an Executor can be passed to the New function of any sqlc package
+type Executor interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// TxFromContext returns the pgx.Tx carried by ctx, such as the one started by Manager
+// Inside a savepoint, it is the pgx.Tx of the savepoint
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	return transaction.TxFromContext[pgx.Tx](ctx)
+}
+
+// DBTX returns the executor queries should run on: the pgx.Tx carried by ctx,
+// or fallback (typically the pool) when ctx carries no transaction
+//
+//	queries := db.New(pgxtransaction.DBTX(ctx, pool))
+func DBTX(ctx context.Context, fallback Executor) Executor {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return fallback
+}
@@ -0,0 +1,41 @@
+package pgxtransaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/db"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Executor can be passed to the queries generated by sqlc
+var _ db.DBTX = (Executor)(nil)
+
+func TestDBTX(t *testing.T) {
+	pool := &fakePool{}
+	m := newManager(pool, nil, transaction.Config{})
+	fallback := &fakeTx{name: "pool"}
+
+	_, ok := TxFromContext(context.Background())
+	assert.False(t, ok)
+	assert.Same(t, fallback, DBTX(context.Background(), fallback))
+
+	err := m.ExecTx(context.Background(), func(ctx context.Context) error {
+		tx, ok := TxFromContext(ctx)
+		require.True(t, ok)
+		assert.Same(t, tx, DBTX(ctx, fallback))
+
+		return m.ExecTx(ctx, func(ctx context.Context) error {
+			// Inside a savepoint, the executor is the pgx.Tx of the savepoint
+			sp, ok := TxFromContext(ctx)
+			require.True(t, ok)
+			assert.NotSame(t, tx, sp)
+			assert.Same(t, sp, DBTX(ctx, fallback))
+			return nil
+		})
+	})
+
+	assert.NoError(t, err)
+}
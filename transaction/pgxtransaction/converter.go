@@ -0,0 +1,48 @@
+package pgxtransaction
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/jackc/pgx/v5"
+)
+
+// Converts transaction.TxOptions to pgx.TxOptions
+func toPgxTxOptions(opts transaction.TxOptions) pgx.TxOptions {
+	pgxOpts := pgx.TxOptions{
+		IsoLevel: toPgxIsoLevel(opts.Isolation),
+	}
+	if opts.ReadOnly {
+		pgxOpts.AccessMode = pgx.ReadOnly
+	}
+	if opts.Deferrable {
+		pgxOpts.DeferrableMode = pgx.Deferrable
+	}
+	return pgxOpts
+}
+
+// Converts transaction.IsolationLevel to pgx.TxIsoLevel
+// An empty pgx.TxIsoLevel keeps the server default
+func toPgxIsoLevel(level transaction.IsolationLevel) pgx.TxIsoLevel {
+	switch level {
+	case transaction.LevelReadUncommitted:
+		return pgx.ReadUncommitted
+	case transaction.LevelReadCommitted:
+		return pgx.ReadCommitted
+	case transaction.LevelRepeatableRead:
+		return pgx.RepeatableRead
+	case transaction.LevelSerializable:
+		return pgx.Serializable
+	default:
+		return ""
+	}
+}
+
+// Converts pgx errors to their transaction package counterpart
+func toTxError(err error) error {
+	if errors.Is(err, pgx.ErrTxClosed) {
+		return fmt.Errorf("%w: %w", transaction.ErrTxDone, err)
+	}
+	return err
+}
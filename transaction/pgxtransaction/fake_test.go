@@ -0,0 +1,95 @@
+package pgxtransaction
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakePool is an in-memory stand-in for *pgxpool.Pool that records transaction events
+type fakePool struct {
+	events     []string
+	begins     int
+	options    []pgx.TxOptions
+	commitErrs []error
+}
+
+func (p *fakePool) BeginTx(_ context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	p.begins++
+	p.options = append(p.options, opts)
+
+	tx := &fakeTx{pool: p, name: fmt.Sprintf("tx%d", p.begins)}
+	p.record(tx, "begin")
+	return tx, nil
+}
+
+func (p *fakePool) record(tx *fakeTx, event string) {
+	p.events = append(p.events, tx.name+": "+event)
+}
+
+// fakeTx implements the parts of pgx.Tx used by Manager
+// A fakeTx with depth > 0 represents a savepoint, like the nested transactions of pgx
+type fakeTx struct {
+	pgx.Tx
+	pool   *fakePool
+	name   string
+	depth  int
+	closed bool
+}
+
+func (tx *fakeTx) Begin(_ context.Context) (pgx.Tx, error) {
+	if tx.closed {
+		return nil, pgx.ErrTxClosed
+	}
+
+	sp := &fakeTx{pool: tx.pool, name: tx.name, depth: tx.depth + 1}
+	tx.pool.record(sp, fmt.Sprintf("savepoint %d", sp.depth))
+	return sp, nil
+}
+
+func (tx *fakeTx) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	tx.pool.record(tx, sql)
+	return pgconn.CommandTag{}, nil
+}
+
+func (tx *fakeTx) Commit(_ context.Context) error {
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+
+	if tx.depth > 0 {
+		tx.pool.record(tx, fmt.Sprintf("release %d", tx.depth))
+		return nil
+	}
+	if len(tx.pool.commitErrs) > 0 {
+		err := tx.pool.commitErrs[0]
+		tx.pool.commitErrs = tx.pool.commitErrs[1:]
+		if err != nil {
+			tx.pool.record(tx, "commit failed")
+			return err
+		}
+	}
+	tx.pool.record(tx, "commit")
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	// Like pgx, the ROLLBACK statement cannot be sent with a canceled context
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	tx.closed = true
+
+	if tx.depth > 0 {
+		tx.pool.record(tx, fmt.Sprintf("rollback to %d", tx.depth))
+		return nil
+	}
+	tx.pool.record(tx, "rollback")
+	return nil
+}
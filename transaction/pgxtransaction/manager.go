@@ -2,95 +2,129 @@ package pgxtransaction
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// pgxTxKey is a key for retrieving transaction from context
-type pgxTxKey struct{}
+// beginner is the subset of *pgxpool.Pool used by Manager
+type beginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
 
 // Manager implements the transaction.Manager interface using pgx
 type Manager struct {
-	pool *pgxpool.Pool
+	*transaction.Runner
 }
 
+var _ transaction.Manager = (*Manager)(nil)
+
 // New creates a new Manager with the provided connection pool
-func New(pool *pgxpool.Pool) *Manager {
+func New(pool *pgxpool.Pool, opts ...transaction.Option) *Manager {
+	return newManager(pool, nil, transaction.NewConfig(opts...))
+}
+
+// NewWithCluster creates a new Manager starting its transactions on the pools of cluster:
+//   - read-only transactions run on a replica, unless they are SERIALIZABLE,
+//     which hot standbys do not support, or ctx was returned by WithPrimary
+//   - every other transaction runs on the primary
+func NewWithCluster(cluster *Cluster, opts ...transaction.Option) *Manager {
+	return newManager(cluster.primary, cluster, transaction.NewConfig(opts...))
+}
+
+// newManager creates a new Manager starting its transactions on pool, or on the pools of cluster if it is not nil
+func newManager(pool beginner, cluster *Cluster, cfg transaction.Config) *Manager {
 	return &Manager{
-		pool: pool,
+		Runner: transaction.NewRunner(&driver{pool: pool, cluster: cluster, cfg: cfg}, cfg),
 	}
 }
 
-// Begin starts a new transaction
-func (m *Manager) Begin(ctx context.Context) (context.Context, error) {
-	tx, err := m.pool.Begin(ctx)
+// driver implements transaction.Driver with pgx, which implements savepoints as nested transactions
+type driver struct {
+	pool    beginner
+	cluster *Cluster
+	cfg     transaction.Config
+}
+
+// Active reports whether ctx carries a pgx.Tx
+func (d *driver) Active(ctx context.Context) bool {
+	_, ok := TxFromContext(ctx)
+	return ok
+}
+
+// Begin starts a new pgx.Tx and applies the PostgreSQL timeouts
+func (d *driver) Begin(ctx context.Context, opts transaction.TxOptions) (context.Context, error) {
+	tx, err := d.poolFor(ctx, opts).BeginTx(ctx, toPgxTxOptions(opts))
 	if err != nil {
 		return nil, fmt.Errorf("begin pgx transaction: %w", err)
 	}
 
-	txCtx := context.WithValue(ctx, pgxTxKey{}, tx)
-	return txCtx, nil
+	for _, stmt := range d.cfg.TimeoutsFor(opts).SetLocalStatements() {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			_ = tx.Rollback(ctx)
+			return nil, fmt.Errorf("set transaction timeouts: %w", err)
+		}
+	}
+
+	return transaction.WithTx(ctx, tx), nil
 }
 
-// Commit commits the transaction
-func (m *Manager) Commit(ctx context.Context) error {
-	tx, err := getPgxTx(ctx)
+// Savepoint creates a savepoint in the pgx.Tx carried by ctx
+func (d *driver) Savepoint(ctx context.Context) (context.Context, error) {
+	outer, err := getPgxTx(ctx)
 	if err != nil {
-		return fmt.Errorf("get transaction: %w", err)
+		return nil, fmt.Errorf("get transaction: %w", err)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("commit pgx transaction: %w", err)
+	tx, err := outer.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create savepoint: %w", err)
 	}
-
-	return nil
+	return transaction.WithTx(ctx, tx), nil
 }
 
-// Rollback aborts the transaction
-func (m *Manager) Rollback(ctx context.Context) error {
+// Commit commits the pgx.Tx, or releases the savepoint, carried by ctx
+func (d *driver) Commit(ctx context.Context) error {
 	tx, err := getPgxTx(ctx)
 	if err != nil {
 		return fmt.Errorf("get transaction: %w", err)
 	}
 
-	if err := tx.Rollback(ctx); err != nil {
-		return fmt.Errorf("rollback pgx transaction: %w", err)
+	if err := tx.Commit(ctx); err != nil {
+		return &transaction.CommitError{Err: toTxError(err)}
 	}
-
 	return nil
 }
 
-// ExecTx executes a function within a transaction
-func (m *Manager) ExecTx(ctx context.Context, fn func(ctx context.Context) error) error {
-	tx, err := m.pool.Begin(ctx)
+// Rollback aborts the pgx.Tx, or rolls back to the savepoint, carried by ctx
+func (d *driver) Rollback(ctx context.Context) error {
+	tx, err := getPgxTx(ctx)
 	if err != nil {
-		return fmt.Errorf("begin pgx transaction: %w", err)
+		return fmt.Errorf("get transaction: %w", err)
 	}
 
-	txCtx := context.WithValue(ctx, pgxTxKey{}, tx)
-
-	if err := fn(txCtx); err != nil {
-		if rbErr := tx.Rollback(ctx); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
-		}
-		return fmt.Errorf("transaction failed: %w", err)
+	// The transaction must be rolled back even if ctx has been canceled or its deadline has expired
+	if err := tx.Rollback(context.WithoutCancel(ctx)); err != nil {
+		return fmt.Errorf("rollback pgx transaction: %w", toTxError(err))
 	}
+	return nil
+}
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("commit pgx transaction: %w", err)
+// poolFor returns the pool a new transaction started with opts runs on
+func (d *driver) poolFor(ctx context.Context, opts transaction.TxOptions) beginner {
+	if d.cluster == nil || !opts.ReadOnly || opts.Isolation == transaction.LevelSerializable || usePrimary(ctx) {
+		return d.pool
 	}
-
-	return nil
+	return d.cluster.replica()
 }
 
 // getPgxTx extracts the pgx.Tx from context
 func getPgxTx(ctx context.Context) (pgx.Tx, error) {
-	tx, ok := ctx.Value(pgxTxKey{}).(pgx.Tx)
+	tx, ok := TxFromContext(ctx)
 	if !ok {
-		return nil, errors.New("pgx transaction not found in context")
+		return nil, transaction.ErrNoTransaction
 	}
 	return tx, nil
 }
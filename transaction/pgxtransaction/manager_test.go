@@ -0,0 +1,572 @@
+package pgxtransaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/db"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/transactiontest"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecTx_Nested(t *testing.T) {
+	errInner := errors.New("inner failed")
+	errOuter := errors.New("outer failed")
+
+	tests := map[string]struct {
+		inner          func(ctx context.Context) error
+		outerErr       error
+		expectedError  assert.ErrorAssertionFunc
+		expectedEvents []string
+	}{
+		"success - inner unit is released and outer committed": {
+			inner:         func(ctx context.Context) error { return nil },
+			expectedError: assert.NoError,
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: savepoint 1",
+				"tx1: release 1",
+				"tx1: commit",
+			},
+		},
+		"success - inner failure only rolls back to the savepoint": {
+			inner:         func(ctx context.Context) error { return errInner },
+			expectedError: assert.NoError,
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: savepoint 1",
+				"tx1: rollback to 1",
+				"tx1: commit",
+			},
+		},
+		"error - outer failure rolls back the released inner unit": {
+			inner:         func(ctx context.Context) error { return nil },
+			outerErr:      errOuter,
+			expectedError: assert.Error,
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: savepoint 1",
+				"tx1: release 1",
+				"tx1: rollback",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool := &fakePool{}
+			m := newManager(pool, nil, transaction.Config{})
+
+			err := m.ExecTx(context.Background(), func(ctx context.Context) error {
+				// The inner error is deliberately ignored: the outer unit decides on its own
+				_ = m.ExecTx(ctx, tt.inner)
+				return tt.outerErr
+			})
+
+			tt.expectedError(t, err)
+			assert.Equal(t, 1, pool.begins)
+			assert.Equal(t, tt.expectedEvents, pool.events)
+		})
+	}
+}
+
+func TestExecTxWithOptions_Propagation(t *testing.T) {
+	tests := map[string]struct {
+		outer          bool
+		propagation    transaction.Propagation
+		expectedError  error
+		expectedEvents []string
+	}{
+		"nested - creates a savepoint in the outer transaction": {
+			outer:       true,
+			propagation: transaction.PropagationNested,
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: savepoint 1",
+				"fn: tx1 depth 1",
+				"tx1: release 1",
+				"tx1: commit",
+			},
+		},
+		"required - joins the outer transaction": {
+			outer:       true,
+			propagation: transaction.PropagationRequired,
+			expectedEvents: []string{
+				"tx1: begin",
+				"fn: tx1 depth 0",
+				"tx1: commit",
+			},
+		},
+		"required - starts a transaction when there is none": {
+			propagation: transaction.PropagationRequired,
+			expectedEvents: []string{
+				"tx1: begin",
+				"fn: tx1 depth 0",
+				"tx1: commit",
+			},
+		},
+		"requires new - starts a second transaction": {
+			outer:       true,
+			propagation: transaction.PropagationRequiresNew,
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx2: begin",
+				"fn: tx2 depth 0",
+				"tx2: commit",
+				"tx1: commit",
+			},
+		},
+		"mandatory - joins the outer transaction": {
+			outer:       true,
+			propagation: transaction.PropagationMandatory,
+			expectedEvents: []string{
+				"tx1: begin",
+				"fn: tx1 depth 0",
+				"tx1: commit",
+			},
+		},
+		"mandatory - fails without a transaction": {
+			propagation:   transaction.PropagationMandatory,
+			expectedError: transaction.ErrNoTransaction,
+		},
+		"never - runs without a transaction": {
+			propagation: transaction.PropagationNever,
+			expectedEvents: []string{
+				"fn: no transaction",
+			},
+		},
+		"never - fails inside a transaction": {
+			outer:         true,
+			propagation:   transaction.PropagationNever,
+			expectedError: transaction.ErrTransactionExists,
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: rollback",
+			},
+		},
+		"supported - joins the outer transaction": {
+			outer:       true,
+			propagation: transaction.PropagationSupported,
+			expectedEvents: []string{
+				"tx1: begin",
+				"fn: tx1 depth 0",
+				"tx1: commit",
+			},
+		},
+		"supported - runs without a transaction": {
+			propagation: transaction.PropagationSupported,
+			expectedEvents: []string{
+				"fn: no transaction",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool := &fakePool{}
+			m := newManager(pool, nil, transaction.Config{})
+
+			inner := func(ctx context.Context) error {
+				return m.ExecTxWithOptions(ctx, transaction.TxOptions{Propagation: tt.propagation}, func(ctx context.Context) error {
+					pool.events = append(pool.events, "fn: "+describeTx(ctx))
+					return nil
+				})
+			}
+
+			var err error
+			if tt.outer {
+				err = m.ExecTx(context.Background(), inner)
+			} else {
+				err = inner(context.Background())
+			}
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedEvents, pool.events)
+		})
+	}
+}
+
+// describeTx describes the fake transaction carried by ctx
+func describeTx(ctx context.Context) string {
+	tx, ok := transaction.TxFromContext[pgx.Tx](ctx)
+	if !ok {
+		return "no transaction"
+	}
+	fake := tx.(*fakeTx)
+	return fmt.Sprintf("%s depth %d", fake.name, fake.depth)
+}
+
+func TestExecTx_Retry(t *testing.T) {
+	errSerialization := &pgconn.PgError{Code: "40001"}
+
+	tests := map[string]struct {
+		cfgRetry       transaction.RetryPolicy
+		callRetry      *transaction.RetryPolicy
+		nested         bool
+		commitErrs     []error
+		expectedError  error
+		expectedBegins int
+		expectedCalls  int
+	}{
+		"success - serialization failure is retried in a fresh transaction": {
+			cfgRetry:       transaction.RetryPolicy{MaxAttempts: 3},
+			commitErrs:     []error{errSerialization, nil},
+			expectedBegins: 2,
+			expectedCalls:  2,
+		},
+		"error - attempts exhausted": {
+			cfgRetry:       transaction.RetryPolicy{MaxAttempts: 2},
+			commitErrs:     []error{errSerialization, errSerialization},
+			expectedError:  errSerialization,
+			expectedBegins: 2,
+			expectedCalls:  2,
+		},
+		"error - retries disabled by default": {
+			commitErrs:     []error{errSerialization},
+			expectedError:  errSerialization,
+			expectedBegins: 1,
+			expectedCalls:  1,
+		},
+		"success - per-call policy overrides the manager": {
+			callRetry:      &transaction.RetryPolicy{MaxAttempts: 2},
+			commitErrs:     []error{errSerialization, nil},
+			expectedBegins: 2,
+			expectedCalls:  2,
+		},
+		"success - outermost transaction retries a nested failure": {
+			cfgRetry:       transaction.RetryPolicy{MaxAttempts: 2},
+			nested:         true,
+			commitErrs:     []error{errSerialization, nil},
+			expectedBegins: 2,
+			expectedCalls:  2,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool := &fakePool{commitErrs: tt.commitErrs}
+			m := newManager(pool, nil, transaction.NewConfig(transaction.WithRetryPolicy(tt.cfgRetry)))
+
+			calls := 0
+			fn := func(ctx context.Context) error {
+				calls++
+				return nil
+			}
+			if tt.nested {
+				fn = func(ctx context.Context) error {
+					// The savepoint itself is never retried
+					return m.ExecTxWithOptions(ctx, transaction.TxOptions{Retry: &transaction.RetryPolicy{MaxAttempts: 5}}, func(ctx context.Context) error {
+						calls++
+						return nil
+					})
+				}
+			}
+
+			err := m.ExecTxWithOptions(context.Background(), transaction.TxOptions{Retry: tt.callRetry}, fn)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedBegins, pool.begins)
+			assert.Equal(t, tt.expectedCalls, calls)
+		})
+	}
+}
+
+func TestExecTx_Panic(t *testing.T) {
+	tests := map[string]struct {
+		panicAsError   bool
+		nested         bool
+		expectedEvents []string
+	}{
+		"re-panics after rolling back": {
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: rollback",
+			},
+		},
+		"re-panics after rolling back the savepoint and the outer transaction": {
+			nested: true,
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: savepoint 1",
+				"tx1: rollback to 1",
+				"tx1: rollback",
+			},
+		},
+		"returns a PanicError after rolling back": {
+			panicAsError: true,
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: rollback",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool := &fakePool{}
+			var cfg transaction.Config
+			if tt.panicAsError {
+				cfg = transaction.NewConfig(transaction.WithPanicAsError())
+			}
+			m := newManager(pool, nil, cfg)
+
+			fn := func(ctx context.Context) error {
+				panic("boom")
+			}
+			if tt.nested {
+				inner := fn
+				fn = func(ctx context.Context) error {
+					return m.ExecTx(ctx, inner)
+				}
+			}
+
+			if tt.panicAsError {
+				err := m.ExecTx(context.Background(), fn)
+
+				var panicErr *transaction.PanicError
+				if assert.ErrorAs(t, err, &panicErr) {
+					assert.Equal(t, "boom", panicErr.Value)
+					assert.Contains(t, string(panicErr.Stack), "TestExecTx_Panic")
+				}
+			} else {
+				assert.PanicsWithValue(t, "boom", func() {
+					_ = m.ExecTx(context.Background(), fn)
+				})
+			}
+
+			assert.Equal(t, tt.expectedEvents, pool.events)
+		})
+	}
+}
+
+func TestExecTx_Timeouts(t *testing.T) {
+	cfgTimeouts := transaction.Timeouts{Statement: time.Second, Lock: 100 * time.Millisecond}
+
+	tests := map[string]struct {
+		callTimeouts   *transaction.Timeouts
+		nested         bool
+		expectedEvents []string
+	}{
+		"manager timeouts are set first in the transaction": {
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: SET LOCAL statement_timeout = 1000",
+				"tx1: SET LOCAL lock_timeout = 100",
+				"tx1: commit",
+			},
+		},
+		"per-call timeouts override the manager": {
+			callTimeouts: &transaction.Timeouts{IdleInTransaction: time.Minute},
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: SET LOCAL idle_in_transaction_session_timeout = 60000",
+				"tx1: commit",
+			},
+		},
+		"savepoints keep the timeouts of the transaction": {
+			callTimeouts: &transaction.Timeouts{Statement: time.Minute},
+			nested:       true,
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: SET LOCAL statement_timeout = 1000",
+				"tx1: SET LOCAL lock_timeout = 100",
+				"tx1: savepoint 1",
+				"tx1: release 1",
+				"tx1: commit",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool := &fakePool{}
+			m := newManager(pool, nil, transaction.NewConfig(transaction.WithTimeouts(cfgTimeouts)))
+
+			fn := func(ctx context.Context) error { return nil }
+			opts := transaction.TxOptions{Timeouts: tt.callTimeouts}
+			if tt.nested {
+				inner := opts
+				opts = transaction.TxOptions{}
+				fn = func(ctx context.Context) error {
+					return m.ExecTxWithOptions(ctx, inner, func(ctx context.Context) error { return nil })
+				}
+			}
+
+			err := m.ExecTxWithOptions(context.Background(), opts, fn)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, pool.events)
+		})
+	}
+}
+
+func TestManagerSuite_Postgres(t *testing.T) {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	transactiontest.RunManagerSuite(t, func(t *testing.T) transactiontest.Harness {
+		table := pgx.Identifier{"transactiontest_" + strings.ReplaceAll(uuid.NewString(), "-", "")}.Sanitize()
+
+		_, err := pool.Exec(context.Background(), "CREATE TABLE "+table+" (name TEXT NOT NULL)")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = pool.Exec(context.Background(), "DROP TABLE "+table)
+		})
+
+		return transactiontest.Harness{
+			Manager: New(pool),
+			Insert: func(ctx context.Context, key string) error {
+				_, err := transaction.Executor[db.DBTX](ctx, pool).Exec(ctx, "INSERT INTO "+table+" (name) VALUES ($1)", key)
+				return err
+			},
+			Exists: func(ctx context.Context, key string) (bool, error) {
+				var exists bool
+				err := pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM "+table+" WHERE name = $1)", key).Scan(&exists)
+				return exists, err
+			},
+		}
+	})
+}
+
+func TestExecTx_Deadline(t *testing.T) {
+	tests := map[string]struct {
+		nested         bool
+		expectedEvents []string
+	}{
+		"transaction is rolled back after its deadline": {
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: rollback",
+			},
+		},
+		"savepoint is rolled back after the deadline of the transaction": {
+			nested: true,
+			expectedEvents: []string{
+				"tx1: begin",
+				"tx1: savepoint 1",
+				"tx1: rollback to 1",
+				"tx1: rollback",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool := &fakePool{}
+			m := newManager(pool, nil, transaction.Config{})
+
+			fn := func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			if tt.nested {
+				inner := fn
+				fn = func(ctx context.Context) error {
+					return m.ExecTx(ctx, inner)
+				}
+			}
+
+			opts := transaction.TxOptions{Timeouts: &transaction.Timeouts{Transaction: 10 * time.Millisecond}}
+			err := m.ExecTxWithOptions(context.Background(), opts, fn)
+
+			assert.ErrorIs(t, err, transaction.ErrTimeout)
+			var rbErr *transaction.RollbackError
+			assert.False(t, errors.As(err, &rbErr), "unexpected rollback error: %v", err)
+			assert.Equal(t, tt.expectedEvents, pool.events)
+		})
+	}
+}
+
+func TestExecTx_CanceledStatement(t *testing.T) {
+	// queryCanceled is the error PostgreSQL answers the cancel request of pgx with, and reports an expired statement_timeout with
+	queryCanceled := &pgconn.PgError{Code: "57014", Message: "canceling statement due to user request"}
+
+	tests := map[string]struct {
+		ctx             func() (context.Context, context.CancelFunc)
+		timeouts        transaction.Timeouts
+		expectedTimeout bool
+	}{
+		"caller cancels its context during the statement": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(10*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			timeouts:        transaction.Timeouts{Transaction: time.Minute},
+			expectedTimeout: false,
+		},
+		"deadline of the caller expires during the statement": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			timeouts:        transaction.Timeouts{Transaction: time.Minute},
+			expectedTimeout: false,
+		},
+		"transaction deadline expires during the statement": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			timeouts:        transaction.Timeouts{Transaction: 10 * time.Millisecond},
+			expectedTimeout: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool := &fakePool{}
+			m := newManager(pool, nil, transaction.NewConfig(transaction.WithTimeouts(tt.timeouts)))
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			err := m.ExecTx(ctx, func(ctx context.Context) error {
+				// The statement runs until pgx cancels it because ctx is done
+				<-ctx.Done()
+				return queryCanceled
+			})
+
+			assert.ErrorIs(t, err, queryCanceled)
+			assert.Equal(t, tt.expectedTimeout, errors.Is(err, transaction.ErrTimeout), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestExecTx_CanceledStatement_Postgres(t *testing.T) {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	m := New(pool, transaction.WithTimeouts(transaction.Timeouts{Transaction: time.Minute}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	err = m.ExecTx(ctx, func(ctx context.Context) error {
+		_, err := transaction.Executor[db.DBTX](ctx, pool).Exec(ctx, "SELECT pg_sleep(10)")
+		return err
+	})
+
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, transaction.ErrTimeout), "caller cancellation reported as a timeout: %v", err)
+}
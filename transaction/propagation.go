@@ -0,0 +1,114 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+)
+
+// Propagation defines how a unit of work relates to a transaction already carried by the context
+type Propagation int
+
+const (
+	// PropagationNested runs in a savepoint of the current transaction, or in a new transaction if there is none
+	// This is the default
+	PropagationNested Propagation = iota
+
+	// PropagationRequired joins the current transaction, or starts a new one if there is none
+	PropagationRequired
+
+	// PropagationRequiresNew always starts a new transaction on another connection,
+	// suspending the current one until it completes
+	PropagationRequiresNew
+
+	// PropagationMandatory joins the current transaction and fails with ErrNoTransaction if there is none
+	PropagationMandatory
+
+	// PropagationNever runs without a transaction and fails with ErrTransactionExists if there is one
+	PropagationNever
+
+	// PropagationSupported joins the current transaction, or runs without one if there is none
+	PropagationSupported
+)
+
+// String returns the name of the propagation mode
+func (p Propagation) String() string {
+	switch p {
+	case PropagationNested:
+		return "NESTED"
+	case PropagationRequired:
+		return "REQUIRED"
+	case PropagationRequiresNew:
+		return "REQUIRES_NEW"
+	case PropagationMandatory:
+		return "MANDATORY"
+	case PropagationNever:
+		return "NEVER"
+	case PropagationSupported:
+		return "SUPPORTED"
+	default:
+		return fmt.Sprintf("Propagation(%d)", int(p))
+	}
+}
+
+// Scope is what a Manager does to run a unit of work
+type Scope int
+
+const (
+	// ScopeNew starts a new transaction
+	ScopeNew Scope = iota
+
+	// ScopeSavepoint creates a savepoint in the current transaction
+	ScopeSavepoint
+
+	// ScopeJoin runs in the current transaction, leaving commit and rollback to its owner
+	ScopeJoin
+
+	// ScopeNone runs without a transaction
+	ScopeNone
+)
+
+// ResolvePropagation returns the scope of a unit of work with the given propagation,
+// depending on whether the context already carries a transaction
+func ResolvePropagation(p Propagation, active bool) (Scope, error) {
+	switch p {
+	case PropagationNested:
+		if active {
+			return ScopeSavepoint, nil
+		}
+		return ScopeNew, nil
+	case PropagationRequired:
+		if active {
+			return ScopeJoin, nil
+		}
+		return ScopeNew, nil
+	case PropagationRequiresNew:
+		return ScopeNew, nil
+	case PropagationMandatory:
+		if active {
+			return ScopeJoin, nil
+		}
+		return 0, ErrNoTransaction
+	case PropagationNever:
+		if active {
+			return 0, ErrTransactionExists
+		}
+		return ScopeNone, nil
+	case PropagationSupported:
+		if active {
+			return ScopeJoin, nil
+		}
+		return ScopeNone, nil
+	default:
+		return 0, fmt.Errorf("unknown propagation: %v", p)
+	}
+}
+
+// StartsTransaction reports whether a unit of work started with opts runs in a new transaction,
+// rather than in a savepoint, in the transaction carried by ctx or without a transaction
+// Invalid propagations are reported as starting none, the Manager rejecting them
+// Decorators use it to observe transactions, not the units of work run in them
+func StartsTransaction(ctx context.Context, opts TxOptions) bool {
+	_, active := TxFromContext[any](ctx)
+	scope, err := ResolvePropagation(opts.Propagation, active)
+	return err == nil && scope == ScopeNew
+}
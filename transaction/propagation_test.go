@@ -0,0 +1,68 @@
+package transaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePropagation(t *testing.T) {
+	tests := map[string]struct {
+		propagation   Propagation
+		active        bool
+		expectedScope Scope
+		expectedError error
+	}{
+		"nested - without transaction":       {propagation: PropagationNested, active: false, expectedScope: ScopeNew},
+		"nested - with transaction":          {propagation: PropagationNested, active: true, expectedScope: ScopeSavepoint},
+		"required - without transaction":     {propagation: PropagationRequired, active: false, expectedScope: ScopeNew},
+		"required - with transaction":        {propagation: PropagationRequired, active: true, expectedScope: ScopeJoin},
+		"requires new - without transaction": {propagation: PropagationRequiresNew, active: false, expectedScope: ScopeNew},
+		"requires new - with transaction":    {propagation: PropagationRequiresNew, active: true, expectedScope: ScopeNew},
+		"mandatory - without transaction":    {propagation: PropagationMandatory, active: false, expectedError: ErrNoTransaction},
+		"mandatory - with transaction":       {propagation: PropagationMandatory, active: true, expectedScope: ScopeJoin},
+		"never - without transaction":        {propagation: PropagationNever, active: false, expectedScope: ScopeNone},
+		"never - with transaction":           {propagation: PropagationNever, active: true, expectedError: ErrTransactionExists},
+		"supported - without transaction":    {propagation: PropagationSupported, active: false, expectedScope: ScopeNone},
+		"supported - with transaction":       {propagation: PropagationSupported, active: true, expectedScope: ScopeJoin},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			scope, err := ResolvePropagation(tt.propagation, tt.active)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			if tt.expectedError == nil {
+				assert.Equal(t, tt.expectedScope, scope)
+			}
+		})
+	}
+}
+
+func TestStartsTransaction(t *testing.T) {
+	tests := map[string]struct {
+		propagation Propagation
+		active      bool
+		expected    bool
+	}{
+		"nested - without transaction":    {propagation: PropagationNested, active: false, expected: true},
+		"nested - with transaction":       {propagation: PropagationNested, active: true, expected: false},
+		"required - with transaction":     {propagation: PropagationRequired, active: true, expected: false},
+		"requires new - with transaction": {propagation: PropagationRequiresNew, active: true, expected: true},
+		"mandatory - without transaction": {propagation: PropagationMandatory, active: false, expected: false},
+		"supported - without transaction": {propagation: PropagationSupported, active: false, expected: false},
+		"unknown propagation":             {propagation: Propagation(100), active: false, expected: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.active {
+				ctx = WithTx(ctx, "tx")
+			}
+
+			assert.Equal(t, tt.expected, StartsTransaction(ctx, TxOptions{Propagation: tt.propagation}))
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// SQLSTATE codes of the errors that are worth retrying in a fresh transaction
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// RetryPolicy controls how ExecTx replays a transaction that failed with a retryable error
+// Only transactions started by ExecTx are retried; nested units of work leave it to the outermost one
+// Every attempt gets a Timeouts.Transaction deadline of its own; bound the whole call with the context to limit it
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the transaction is run, including the first one
+	// A value of 0 or 1 disables retries
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, doubled for every following one
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between two attempts, if positive
+	MaxDelay time.Duration
+
+	// Jitter is the fraction of the delay, between 0 and 1, that is randomized
+	// to keep conflicting transactions from retrying in lockstep
+	Jitter float64
+
+	// Retryable reports whether a failed transaction should be run again
+	// IsRetryable is used when nil
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy returns a policy that runs a transaction up to 3 times
+// with an exponential backoff starting at 10ms
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   10 * time.Millisecond,
+		MaxDelay:    time.Second,
+		Jitter:      0.5,
+	}
+}
+
+// RetryError is returned when a transaction still failed after being run more than once
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("transaction failed after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether err is a serialization failure (SQLSTATE 40001) or a deadlock (SQLSTATE 40P01)
+// It recognizes any error exposing its SQLSTATE through a SQLState() method,
+// such as *pgconn.PgError (pgx, pgx stdlib) and *pq.Error (lib/pq)
+func IsRetryable(err error) bool {
+	var sqlErr interface{ SQLState() string }
+	if !errors.As(err, &sqlErr) {
+		return false
+	}
+
+	switch sqlErr.SQLState() {
+	case sqlStateSerializationFailure, sqlStateDeadlockDetected:
+		return true
+	default:
+		return false
+	}
+}
+
+// Retry runs fn, and runs it again after a backoff while it fails with an error the policy considers retryable
+// If fn was run more than once, the last error is returned wrapped in a *RetryError
+func Retry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= policy.MaxAttempts || !retryable(err) {
+			if attempt > 1 {
+				return &RetryError{Attempts: attempt, Err: err}
+			}
+			return err
+		}
+
+		timer := time.NewTimer(policy.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &RetryError{Attempts: attempt, Err: errors.Join(err, ctx.Err())}
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns the backoff before the attempt following the given one
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << min(attempt-1, 32)
+	if p.MaxDelay > 0 && (d < 0 || d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		jitter := min(p.Jitter, 1)
+		d -= time.Duration(jitter * rand.Float64() * float64(d))
+	}
+
+	return d
+}
@@ -0,0 +1,146 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sqlStateError mimics driver errors such as *pgconn.PgError
+type sqlStateError struct {
+	code string
+}
+
+func (e *sqlStateError) Error() string    { return "sqlstate " + e.code }
+func (e *sqlStateError) SQLState() string { return e.code }
+
+func TestIsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"serialization failure":         {err: &sqlStateError{code: "40001"}, expected: true},
+		"deadlock detected":             {err: &sqlStateError{code: "40P01"}, expected: true},
+		"wrapped serialization failure": {err: errors.Join(errors.New("commit"), &sqlStateError{code: "40001"}), expected: true},
+		"unique violation":              {err: &sqlStateError{code: "23505"}, expected: false},
+		"error without sqlstate":        {err: errors.New("boom"), expected: false},
+		"nil":                           {err: nil, expected: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errSerialization := &sqlStateError{code: "40001"}
+	errOther := errors.New("boom")
+
+	tests := map[string]struct {
+		policy           RetryPolicy
+		errs             []error
+		expectedAttempts int
+		expectedError    error
+		expectedRetryErr bool
+	}{
+		"success - first attempt": {
+			policy:           RetryPolicy{MaxAttempts: 3},
+			errs:             []error{nil},
+			expectedAttempts: 1,
+		},
+		"success - after retryable failures": {
+			policy:           RetryPolicy{MaxAttempts: 3},
+			errs:             []error{errSerialization, errSerialization, nil},
+			expectedAttempts: 3,
+		},
+		"error - attempts exhausted": {
+			policy:           RetryPolicy{MaxAttempts: 2},
+			errs:             []error{errSerialization, errSerialization},
+			expectedAttempts: 2,
+			expectedError:    errSerialization,
+			expectedRetryErr: true,
+		},
+		"error - not retryable": {
+			policy:           RetryPolicy{MaxAttempts: 3},
+			errs:             []error{errOther},
+			expectedAttempts: 1,
+			expectedError:    errOther,
+		},
+		"error - not retryable after a retry": {
+			policy:           RetryPolicy{MaxAttempts: 3},
+			errs:             []error{errSerialization, errOther},
+			expectedAttempts: 2,
+			expectedError:    errOther,
+			expectedRetryErr: true,
+		},
+		"error - retries disabled": {
+			policy:           RetryPolicy{},
+			errs:             []error{errSerialization},
+			expectedAttempts: 1,
+			expectedError:    errSerialization,
+		},
+		"success - custom classifier": {
+			policy: RetryPolicy{
+				MaxAttempts: 2,
+				Retryable:   func(err error) bool { return errors.Is(err, errOther) },
+			},
+			errs:             []error{errOther, nil},
+			expectedAttempts: 2,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			err := Retry(context.Background(), tt.policy, func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+
+			assert.Equal(t, tt.expectedAttempts, attempts)
+			assert.ErrorIs(t, err, tt.expectedError)
+
+			var retryErr *RetryError
+			assert.Equal(t, tt.expectedRetryErr, errors.As(err, &retryErr))
+			if tt.expectedRetryErr {
+				assert.Equal(t, tt.expectedAttempts, retryErr.Attempts)
+			}
+		})
+	}
+}
+
+func TestRetry_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	errSerialization := &sqlStateError{code: "40001"}
+	err := Retry(ctx, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour}, func() error {
+		return errSerialization
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, errSerialization)
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	assert.Equal(t, 10*time.Millisecond, policy.delay(1))
+	assert.Equal(t, 20*time.Millisecond, policy.delay(2))
+	assert.Equal(t, 40*time.Millisecond, policy.delay(3))
+	assert.Equal(t, 50*time.Millisecond, policy.delay(4))
+	assert.Equal(t, 50*time.Millisecond, policy.delay(100))
+
+	policy.Jitter = 0.5
+	for range 100 {
+		d := policy.delay(2)
+		assert.GreaterOrEqual(t, d, 10*time.Millisecond)
+		assert.LessOrEqual(t, d, 20*time.Millisecond)
+	}
+}
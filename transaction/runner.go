@@ -0,0 +1,230 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Driver starts and ends the transactions of a database driver on behalf of a Runner
+type Driver interface {
+	// Active reports whether ctx carries a transaction of the driver
+	Active(ctx context.Context) bool
+
+	// Begin starts a new transaction with opts and returns a copy of ctx carrying it
+	Begin(ctx context.Context, opts TxOptions) (context.Context, error)
+
+	// Savepoint creates a savepoint in the transaction carried by ctx and returns a copy of ctx carrying it
+	Savepoint(ctx context.Context) (context.Context, error)
+
+	// Commit commits the transaction, or releases the savepoint, carried by ctx
+	Commit(ctx context.Context) error
+
+	// Rollback aborts the transaction, or rolls back to the savepoint, carried by ctx
+	Rollback(ctx context.Context) error
+}
+
+// OptionsAdapter is implemented by the Drivers of databases that do not support every TxOptions
+// The Runner passes the options of every transaction through AdaptOptions before using them
+type OptionsAdapter interface {
+	// AdaptOptions returns opts with the options the database does not support dropped or replaced
+	AdaptOptions(opts TxOptions) TxOptions
+}
+
+// Runner implements Manager on top of a Driver, with the behavior shared by every implementation:
+// propagation, retries, the Transaction deadline, panic recovery and hooks
+type Runner struct {
+	driver Driver
+	cfg    Config
+}
+
+var _ Manager = (*Runner)(nil)
+
+// NewRunner creates a new Runner starting its transactions with driver
+func NewRunner(driver Driver, cfg Config) *Runner {
+	return &Runner{
+		driver: driver,
+		cfg:    cfg,
+	}
+}
+
+// Begin starts a new transaction
+// If ctx already carries a transaction, a savepoint is created in it instead
+func (r *Runner) Begin(ctx context.Context) (context.Context, error) {
+	return r.BeginWithOptions(ctx, TxOptions{})
+}
+
+// BeginWithOptions starts a new transaction with the given options
+// Only the propagation modes that start a transaction or a savepoint are supported here;
+// use ExecTxWithOptions to join the current transaction or to run without one
+func (r *Runner) BeginWithOptions(ctx context.Context, opts TxOptions) (context.Context, error) {
+	opts = r.adaptOptions(opts)
+	scope, err := ResolvePropagation(opts.Propagation, r.driver.Active(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if scope != ScopeNew && scope != ScopeSavepoint {
+		return nil, fmt.Errorf("propagation %v is not supported by Begin", opts.Propagation)
+	}
+
+	return r.begin(ctx, scope, opts)
+}
+
+// Commit commits the transaction, or releases the savepoint if ctx carries one
+// The hooks registered in the transaction run around the commit; if a before-commit hook panics,
+// the transaction is rolled back before the panic goes on
+func (r *Runner) Commit(ctx context.Context) error {
+	if !r.driver.Active(ctx) {
+		return fmt.Errorf("get transaction: %w", ErrNoTransaction)
+	}
+
+	committing, finished := false, false
+	defer func() {
+		if !finished && !committing {
+			// A before-commit hook panicked or called runtime.Goexit: the transaction must not leak
+			_ = r.Rollback(ctx)
+		}
+	}()
+
+	err := r.commit(ctx, &committing)
+	finished = true
+	return err
+}
+
+// Rollback aborts the transaction, or rolls back to the savepoint if ctx carries one
+// The after-rollback hooks registered in the transaction or savepoint run afterwards
+func (r *Runner) Rollback(ctx context.Context) error {
+	if !r.driver.Active(ctx) {
+		return fmt.Errorf("get transaction: %w", ErrNoTransaction)
+	}
+
+	return HooksFromContext(ctx).Rollback(ctx, func() error {
+		return r.driver.Rollback(ctx)
+	})
+}
+
+// ExecTx executes a function within a transaction
+// If ctx already carries a transaction, the function runs in a savepoint so that
+// its failure only rolls back its own work (PropagationNested)
+func (r *Runner) ExecTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.ExecTxWithOptions(ctx, TxOptions{}, fn)
+}
+
+// ExecTxWithOptions executes a function within a transaction started with the given options
+// opts.Propagation decides whether the function runs in a new transaction, in a savepoint,
+// in the current transaction or without a transaction
+// A new transaction failing with a retryable error is run again according to the retry policy
+func (r *Runner) ExecTxWithOptions(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	opts = r.adaptOptions(opts)
+	scope, err := ResolvePropagation(opts.Propagation, r.driver.Active(ctx))
+	if err != nil {
+		return err
+	}
+
+	switch scope {
+	case ScopeJoin, ScopeNone:
+		return fn(ctx)
+	case ScopeSavepoint:
+		return r.execTx(ctx, scope, opts, fn)
+	default:
+		return Retry(ctx, r.cfg.RetryPolicyFor(opts), func() error {
+			return r.execTx(ctx, scope, opts, fn)
+		})
+	}
+}
+
+// ExecReadOnly executes a function within a read-only transaction, see ReadOnlyTxOptions
+// If ctx already carries a transaction, the function runs in it
+func (r *Runner) ExecReadOnly(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.ExecTxWithOptions(ctx, ReadOnlyTxOptions(), fn)
+}
+
+// adaptOptions passes opts through the driver if it is an OptionsAdapter
+func (r *Runner) adaptOptions(opts TxOptions) TxOptions {
+	if adapter, ok := r.driver.(OptionsAdapter); ok {
+		return adapter.AdaptOptions(opts)
+	}
+	return opts
+}
+
+// execTx runs fn once in a new transaction or savepoint, depending on scope
+// The transaction is rolled back if fn or a before-commit hook panics, and the panic is propagated
+// or returned as a *PanicError
+// A new transaction is bounded by the Transaction timeout, whose expiry is reported as ErrTimeout
+func (r *Runner) execTx(ctx context.Context, scope Scope, opts TxOptions, fn func(ctx context.Context) error) (err error) {
+	if scope == ScopeNew {
+		var cancel context.CancelFunc
+		ctx, cancel = r.cfg.TimeoutsFor(opts).WithDeadline(ctx)
+		defer cancel()
+		defer func() {
+			err = WithTimeoutError(ctx, err)
+		}()
+	}
+
+	txCtx, err := r.begin(ctx, scope, opts)
+	if err != nil {
+		return err
+	}
+
+	// done is set once the transaction has been ended, committing once the before-commit hooks have run
+	done, committing := false, false
+	defer func() {
+		if done || committing {
+			return
+		}
+
+		// Either fn or a before-commit hook panicked, or one of them called runtime.Goexit:
+		// the transaction must not leak in both cases
+		p := recover()
+		rbErr := r.Rollback(txCtx)
+		if p == nil {
+			return
+		}
+		if !r.cfg.PanicAsError {
+			panic(p)
+		}
+
+		err = WithRollback(NewPanicError(p), rbErr)
+	}()
+
+	if fnErr := fn(txCtx); fnErr != nil {
+		done = true
+		return WithRollback(fmt.Errorf("transaction failed: %w", fnErr), r.Rollback(txCtx))
+	}
+
+	err = r.commit(txCtx, &committing)
+	done = true
+	return err
+}
+
+// begin starts a new transaction or creates a savepoint in the current one, depending on scope,
+// with a hook scope of its own
+// The context of a new transaction records how long it took to begin, see BeginDurationFromContext
+func (r *Runner) begin(ctx context.Context, scope Scope, opts TxOptions) (context.Context, error) {
+	if scope == ScopeSavepoint {
+		spCtx, err := r.driver.Savepoint(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return WithNestedHooks(spCtx), nil
+	}
+
+	start := time.Now()
+	txCtx, err := r.driver.Begin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return WithHooks(withBeginDuration(txCtx, time.Since(start))), nil
+}
+
+// commit commits the transaction or releases the savepoint carried by ctx, running its hooks
+// committing is set once the before-commit hooks have run, right before the driver commits
+func (r *Runner) commit(ctx context.Context, committing *bool) error {
+	return HooksFromContext(ctx).Commit(ctx, func() error {
+		*committing = true
+		return r.driver.Commit(ctx)
+	}, func() error {
+		return r.driver.Rollback(ctx)
+	})
+}
@@ -0,0 +1,163 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowDriver is a Driver whose transactions take beginDelay to begin
+type slowDriver struct {
+	beginDelay time.Duration
+}
+
+func (d slowDriver) Active(ctx context.Context) bool {
+	_, ok := TxFromContext[string](ctx)
+	return ok
+}
+
+func (d slowDriver) Begin(ctx context.Context, _ TxOptions) (context.Context, error) {
+	time.Sleep(d.beginDelay)
+	return WithTx(ctx, "tx"), nil
+}
+
+func (d slowDriver) Savepoint(ctx context.Context) (context.Context, error) { return ctx, nil }
+
+func (d slowDriver) Commit(context.Context) error { return nil }
+
+func (d slowDriver) Rollback(context.Context) error { return nil }
+
+func TestRunner_BeginDuration(t *testing.T) {
+	const delay = 10 * time.Millisecond
+	errRetry := errors.New("retry")
+
+	tests := map[string]struct {
+		run              func(r *Runner, fn func(ctx context.Context) error) error
+		expectedAttempts int
+	}{
+		"ExecTx": {
+			run: func(r *Runner, fn func(ctx context.Context) error) error {
+				return r.ExecTx(context.Background(), fn)
+			},
+			expectedAttempts: 1,
+		},
+		"every attempt of a retried transaction": {
+			run: func(r *Runner, fn func(ctx context.Context) error) error {
+				attempts := 0
+				return r.ExecTx(context.Background(), func(ctx context.Context) error {
+					if err := fn(ctx); err != nil {
+						return err
+					}
+					attempts++
+					if attempts < 2 {
+						return errRetry
+					}
+					return nil
+				})
+			},
+			expectedAttempts: 2,
+		},
+		"Begin": {
+			run: func(r *Runner, fn func(ctx context.Context) error) error {
+				ctx, err := r.Begin(context.Background())
+				if err != nil {
+					return err
+				}
+				if err := fn(ctx); err != nil {
+					return err
+				}
+				return r.Commit(ctx)
+			},
+			expectedAttempts: 1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewRunner(slowDriver{beginDelay: delay}, NewConfig(WithRetryPolicy(RetryPolicy{
+				MaxAttempts: 2,
+				Retryable:   func(err error) bool { return errors.Is(err, errRetry) },
+			})))
+
+			var durations []time.Duration
+			err := tt.run(r, func(ctx context.Context) error {
+				d, ok := BeginDurationFromContext(ctx)
+				require.True(t, ok)
+				durations = append(durations, d)
+				return nil
+			})
+
+			require.NoError(t, err)
+			require.Len(t, durations, tt.expectedAttempts)
+			for _, d := range durations {
+				assert.GreaterOrEqual(t, d, delay)
+			}
+		})
+	}
+}
+
+// adaptingDriver is a Driver without deferrable transactions, recording the options of the transactions it begins
+type adaptingDriver struct {
+	slowDriver
+	began []TxOptions
+}
+
+func (d *adaptingDriver) Begin(ctx context.Context, opts TxOptions) (context.Context, error) {
+	d.began = append(d.began, opts)
+	return d.slowDriver.Begin(ctx, opts)
+}
+
+func (d *adaptingDriver) AdaptOptions(opts TxOptions) TxOptions {
+	opts.Deferrable = false
+	return opts
+}
+
+func TestRunner_AdaptOptions(t *testing.T) {
+	fn := func(context.Context) error { return nil }
+
+	tests := map[string]struct {
+		run      func(r *Runner) error
+		expected TxOptions
+	}{
+		"ExecTxWithOptions": {
+			run: func(r *Runner) error {
+				return r.ExecTxWithOptions(context.Background(), TxOptions{Isolation: LevelSerializable, Deferrable: true}, fn)
+			},
+			expected: TxOptions{Isolation: LevelSerializable},
+		},
+		"ExecReadOnly": {
+			run: func(r *Runner) error {
+				return r.ExecReadOnly(context.Background(), fn)
+			},
+			expected: func() TxOptions {
+				opts := ReadOnlyTxOptions()
+				opts.Deferrable = false
+				return opts
+			}(),
+		},
+		"BeginWithOptions": {
+			run: func(r *Runner) error {
+				ctx, err := r.BeginWithOptions(context.Background(), TxOptions{ReadOnly: true, Deferrable: true})
+				if err != nil {
+					return err
+				}
+				return r.Commit(ctx)
+			},
+			expected: TxOptions{ReadOnly: true},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driver := &adaptingDriver{}
+			r := NewRunner(driver, NewConfig())
+
+			require.NoError(t, tt.run(r))
+			assert.Equal(t, []TxOptions{tt.expected}, driver.began)
+		})
+	}
+}
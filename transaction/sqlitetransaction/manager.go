@@ -0,0 +1,86 @@
+// Package sqlitetransaction implements transaction.Manager for SQLite on top of sqltransaction
+package sqlitetransaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/sqltransaction"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// ErrSingleWriter is returned when starting a new write transaction while ctx already carries one
+// SQLite allows a single writer at a time, so the new transaction would wait for the one suspended by the caller
+var ErrSingleWriter = errors.New("sqlite allows a single write transaction at a time")
+
+// busyTimeout is how long, in milliseconds, a connection waits for the lock held by another writer
+const busyTimeout = "5000"
+
+// Open opens the SQLite database file at path with the settings Manager relies on:
+//   - transactions start with BEGIN IMMEDIATE, so that a transaction takes the write lock upfront
+//     instead of failing with SQLITE_BUSY when it upgrades from reading to writing
+//   - foreign keys are enforced
+//   - connections wait for the lock held by another writer instead of failing right away
+func Open(path string) (*sql.DB, error) {
+	query := url.Values{}
+	query.Set("_txlock", "immediate")
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "busy_timeout("+busyTimeout+")")
+
+	return sql.Open("sqlite", "file:"+path+"?"+query.Encode())
+}
+
+// Manager implements the transaction.Manager interface for SQLite
+//
+// It behaves like sqltransaction.Manager, with the following differences:
+//   - SQLite transactions are always serializable, so the isolation level is ignored
+//   - TxOptions.Deferrable is ignored, SQLite having no SET TRANSACTION statement
+//   - only the Transaction timeout applies; waiting for the lock of another writer is bounded by the busy timeout of Open
+//   - PropagationRequiresNew fails with ErrSingleWriter inside a transaction, unless the new transaction is read-only
+//   - read-only transactions see a consistent snapshot, but the driver does not reject writes in them
+//   - unless a retry policy sets its own Retryable function, SQLITE_BUSY errors are retried
+type Manager struct {
+	*sqltransaction.Manager
+}
+
+var _ transaction.Manager = (*Manager)(nil)
+
+// dialect is how SQLite departs from the PostgreSQL behavior of sqltransaction.Manager
+var dialect = sqltransaction.Dialect{
+	IsRetryable:  IsRetryable,
+	DeadlineOnly: true,
+	NoDeferrable: true,
+	CheckBegin:   checkSingleWriter,
+}
+
+// New creates a new Manager with the provided database connection, which should be opened with Open
+func New(db *sql.DB, opts ...transaction.Option) *Manager {
+	return &Manager{
+		Manager: sqltransaction.NewWithDialect(db, dialect, opts...),
+	}
+}
+
+// IsRetryable reports whether err means that the database was locked by another connection (SQLITE_BUSY),
+// or is a serialization failure or deadlock according to transaction.IsRetryable
+func IsRetryable(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY {
+		return true
+	}
+	return transaction.IsRetryable(err)
+}
+
+// checkSingleWriter rejects a second write transaction started while ctx carries one
+func checkSingleWriter(ctx context.Context, opts transaction.TxOptions) error {
+	if opts.ReadOnly {
+		return nil
+	}
+	if _, active := sqltransaction.TxFromContext(ctx); active {
+		return ErrSingleWriter
+	}
+	return nil
+}
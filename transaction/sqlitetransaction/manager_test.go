@@ -0,0 +1,140 @@
+package sqlitetransaction
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/transactiontest"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDB opens a database in a temporary file with an items table
+func newDB(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Exec("CREATE TABLE items (name TEXT NOT NULL)")
+	require.NoError(t, err)
+	return db, path
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func TestManagerSuite(t *testing.T) {
+	transactiontest.RunManagerSuite(t, func(t *testing.T) transactiontest.Harness {
+		db, _ := newDB(t)
+
+		return transactiontest.Harness{
+			Manager: New(db),
+			Insert: func(ctx context.Context, key string) error {
+				_, err := transaction.Executor[execer](ctx, db).ExecContext(ctx, "INSERT INTO items (name) VALUES (?)", key)
+				return err
+			},
+			Exists: func(ctx context.Context, key string) (bool, error) {
+				var exists bool
+				err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM items WHERE name = ?)", key).Scan(&exists)
+				return exists, err
+			},
+			SingleWriter:     true,
+			WritableReadOnly: true,
+		}
+	})
+}
+
+func TestExecTxWithOptions_SQLite(t *testing.T) {
+	tests := map[string]struct {
+		opts          transaction.TxOptions
+		expectedError error
+	}{
+		"requires new - fails inside a write transaction": {
+			opts:          transaction.TxOptions{Propagation: transaction.PropagationRequiresNew},
+			expectedError: ErrSingleWriter,
+		},
+		"requires new - read-only transaction runs beside the write transaction": {
+			opts: transaction.TxOptions{Propagation: transaction.PropagationRequiresNew, ReadOnly: true},
+		},
+		"nested - deferrable and isolation level are ignored": {
+			opts: transaction.TxOptions{Isolation: transaction.LevelReadCommitted, Deferrable: true},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, _ := newDB(t)
+			m := New(db)
+
+			err := m.ExecTx(context.Background(), func(ctx context.Context) error {
+				return m.ExecTxWithOptions(ctx, tt.opts, func(ctx context.Context) error {
+					var n int
+					return transaction.Executor[querier](ctx, db).QueryRowContext(ctx, "SELECT count(*) FROM items").Scan(&n)
+				})
+			})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestBeginWithOptions_Deferrable(t *testing.T) {
+	db, _ := newDB(t)
+	m := New(db)
+
+	ctx, err := m.BeginWithOptions(context.Background(), transaction.TxOptions{Deferrable: true})
+	require.NoError(t, err)
+	assert.NoError(t, m.Commit(ctx))
+}
+
+func TestNew_Timeouts(t *testing.T) {
+	db, _ := newDB(t)
+	// The PostgreSQL settings are dropped instead of failing the transaction
+	m := New(db, transaction.WithTimeouts(transaction.Timeouts{Statement: time.Second, Lock: time.Second}))
+
+	assert.NoError(t, m.ExecTx(context.Background(), func(ctx context.Context) error { return nil }))
+
+	ctx, err := m.BeginWithOptions(context.Background(), transaction.TxOptions{
+		Timeouts: &transaction.Timeouts{IdleInTransaction: time.Second},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, m.Commit(ctx))
+}
+
+func TestIsRetryable(t *testing.T) {
+	db, path := newDB(t)
+
+	// A connection without busy timeout fails right away while another one holds the write lock
+	other, err := sql.Open("sqlite", "file:"+path+"?_txlock=immediate")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = other.Close() })
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tx.Rollback() })
+
+	_, errBusy := other.Begin()
+	require.Error(t, errBusy)
+
+	_, errSyntax := db.Exec("SELEC 1")
+	require.Error(t, errSyntax)
+
+	assert.True(t, IsRetryable(errBusy), "SQLITE_BUSY")
+	assert.True(t, IsRetryable(&pgconn.PgError{Code: "40001"}), "serialization failure")
+	assert.False(t, IsRetryable(errSyntax), "syntax error")
+}
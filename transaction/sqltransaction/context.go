@@ -0,0 +1,35 @@
+package sqltransaction
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+)
+
+// Executor is implemented by *sql.DB, *sql.Conn and *sql.Tx
+// It has the methods of the DBTX interface generated by sqlc for database/sql,
+// so that an Executor can be passed to the New function of any sqlc package
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// TxFromContext returns the *sql.Tx carried by ctx, such as the one started by Manager
+// It also works for the managers built on top of Manager, like sqlitetransaction and mysqltransaction
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	return transaction.TxFromContext[*sql.Tx](ctx)
+}
+
+// DBTX returns the executor queries should run on: the *sql.Tx carried by ctx,
+// or fallback (typically the *sql.DB) when ctx carries no transaction
+//
+//	queries := sqldb.New(sqltransaction.DBTX(ctx, sqlDB))
+func DBTX(ctx context.Context, fallback Executor) Executor {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return fallback
+}
@@ -0,0 +1,33 @@
+package sqltransaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Executor can be passed to the queries generated by sqlc
+var _ sqldb.DBTX = (Executor)(nil)
+
+func TestDBTX(t *testing.T) {
+	db := newSQLiteDB(t)
+	m := New(db)
+
+	_, ok := TxFromContext(context.Background())
+	assert.False(t, ok)
+	assert.Same(t, db, DBTX(context.Background(), db))
+
+	err := m.ExecTx(context.Background(), func(ctx context.Context) error {
+		tx, ok := TxFromContext(ctx)
+		require.True(t, ok)
+		assert.Same(t, tx, DBTX(ctx, db))
+
+		return insertItem(ctx, db, "a")
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, listItems(t, db))
+}
@@ -0,0 +1,41 @@
+package sqltransaction
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+)
+
+// Converts transaction.TxOptions to *sql.TxOptions
+func toSQLTxOptions(opts transaction.TxOptions) *sql.TxOptions {
+	return &sql.TxOptions{
+		Isolation: toSQLIsolationLevel(opts.Isolation),
+		ReadOnly:  opts.ReadOnly,
+	}
+}
+
+// Converts transaction.IsolationLevel to sql.IsolationLevel
+func toSQLIsolationLevel(level transaction.IsolationLevel) sql.IsolationLevel {
+	switch level {
+	case transaction.LevelReadUncommitted:
+		return sql.LevelReadUncommitted
+	case transaction.LevelReadCommitted:
+		return sql.LevelReadCommitted
+	case transaction.LevelRepeatableRead:
+		return sql.LevelRepeatableRead
+	case transaction.LevelSerializable:
+		return sql.LevelSerializable
+	default:
+		return sql.LevelDefault
+	}
+}
+
+// Converts database/sql errors to their transaction package counterpart
+func toTxError(err error) error {
+	if errors.Is(err, sql.ErrTxDone) {
+		return fmt.Errorf("%w: %w", transaction.ErrTxDone, err)
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package sqltransaction
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+)
+
+// Dialect describes how a database departs from the PostgreSQL behavior Manager assumes
+// The zero value is PostgreSQL
+type Dialect struct {
+	// IsRetryable is the classification of the retry policies that set no Retryable function
+	// If nil, they keep the default one, transaction.IsRetryable
+	IsRetryable func(err error) bool
+
+	// DeadlineOnly drops every timeout but Transaction, the other ones being PostgreSQL settings
+	DeadlineOnly bool
+
+	// NoDeferrable drops TxOptions.Deferrable, for databases without a SET TRANSACTION DEFERRABLE statement
+	NoDeferrable bool
+
+	// CheckBegin, if set, is called before a new transaction is started and aborts it with the returned error
+	// ctx still carries the transaction suspended by PropagationRequiresNew, if any
+	CheckBegin func(ctx context.Context, opts transaction.TxOptions) error
+}
+
+// NewWithDialect is like New for a database that departs from PostgreSQL as described by dialect
+func NewWithDialect(db *sql.DB, dialect Dialect, opts ...transaction.Option) *Manager {
+	cfg := transaction.NewConfig(opts...)
+	cfg.RetryPolicy = dialect.retryPolicy(cfg.RetryPolicy)
+	cfg.Timeouts = dialect.timeouts(cfg.Timeouts)
+
+	return &Manager{
+		Runner: transaction.NewRunner(&driver{db: db, cfg: cfg, dialect: dialect}, cfg),
+	}
+}
+
+// AdaptOptions drops the options the dialect does not support and sets its retry classification
+func (d *driver) AdaptOptions(opts transaction.TxOptions) transaction.TxOptions {
+	if d.dialect.NoDeferrable {
+		opts.Deferrable = false
+	}
+	if opts.Timeouts != nil {
+		timeouts := d.dialect.timeouts(*opts.Timeouts)
+		opts.Timeouts = &timeouts
+	}
+	if opts.Retry != nil {
+		policy := d.dialect.retryPolicy(*opts.Retry)
+		opts.Retry = &policy
+	}
+	return opts
+}
+
+// timeouts returns the timeouts the dialect supports
+func (d Dialect) timeouts(timeouts transaction.Timeouts) transaction.Timeouts {
+	if d.DeadlineOnly {
+		return transaction.Timeouts{Transaction: timeouts.Transaction}
+	}
+	return timeouts
+}
+
+// retryPolicy returns policy with the classification of the dialect if it has none
+func (d Dialect) retryPolicy(policy transaction.RetryPolicy) transaction.RetryPolicy {
+	if policy.Retryable == nil {
+		policy.Retryable = d.IsRetryable
+	}
+	return policy
+}
@@ -5,90 +5,165 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
 )
 
-// txKey is a key for retrieving transaction from context
-type txKey struct{}
+// savepointKey is a key for retrieving the current savepoint depth from context
+type savepointKey struct{}
 
 // Manager implements the transaction.Manager interface using standard SQL
 type Manager struct {
-	db *sql.DB
+	*transaction.Runner
 }
 
+var _ transaction.Manager = (*Manager)(nil)
+
 // New creates a new Manager with the provided database connection
-func New(db *sql.DB) *Manager {
-	return &Manager{
-		db: db,
-	}
+func New(db *sql.DB, opts ...transaction.Option) *Manager {
+	return NewWithDialect(db, Dialect{}, opts...)
+}
+
+// driver implements transaction.Driver with database/sql, savepoints being created with SAVEPOINT statements
+type driver struct {
+	db      *sql.DB
+	cfg     transaction.Config
+	dialect Dialect
 }
 
-// Begin starts a new transaction
-func (m *Manager) Begin(ctx context.Context) (context.Context, error) {
-	tx, err := m.db.BeginTx(ctx, nil)
+var _ transaction.OptionsAdapter = (*driver)(nil)
+
+// Active reports whether ctx carries a sql.Tx
+func (d *driver) Active(ctx context.Context) bool {
+	_, ok := TxFromContext(ctx)
+	return ok
+}
+
+// Begin starts a new sql.Tx
+func (d *driver) Begin(ctx context.Context, opts transaction.TxOptions) (context.Context, error) {
+	if d.dialect.CheckBegin != nil {
+		if err := d.dialect.CheckBegin(ctx, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := d.beginTx(ctx, opts)
 	if err != nil {
-		return nil, fmt.Errorf("begin transaction: %w", err)
+		return nil, err
 	}
 
-	txCtx := context.WithValue(ctx, txKey{}, tx)
-	return txCtx, nil
+	// A new transaction does not inherit the savepoints of a suspended one
+	return context.WithValue(transaction.WithTx(ctx, tx), savepointKey{}, 0), nil
 }
 
-// Commit commits the transaction
-func (m *Manager) Commit(ctx context.Context) error {
+// Savepoint creates a savepoint one level below the one carried by ctx
+func (d *driver) Savepoint(ctx context.Context) (context.Context, error) {
+	tx, err := getTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get transaction: %w", err)
+	}
+	return savepoint(ctx, tx)
+}
+
+// Commit commits the sql.Tx, or releases the savepoint if ctx carries one
+func (d *driver) Commit(ctx context.Context) error {
 	tx, err := getTx(ctx)
 	if err != nil {
 		return fmt.Errorf("get transaction: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit transaction: %w", err)
+	if depth := savepointDepth(ctx); depth > 0 {
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepointName(depth)); err != nil {
+			return &transaction.CommitError{Err: fmt.Errorf("release savepoint: %w", err)}
+		}
+		return nil
 	}
 
+	if err := tx.Commit(); err != nil {
+		return &transaction.CommitError{Err: toTxError(err)}
+	}
 	return nil
 }
 
-// Rollback aborts the transaction
-func (m *Manager) Rollback(ctx context.Context) error {
+// Rollback aborts the sql.Tx, or rolls back to the savepoint if ctx carries one
+func (d *driver) Rollback(ctx context.Context) error {
 	tx, err := getTx(ctx)
 	if err != nil {
 		return fmt.Errorf("get transaction: %w", err)
 	}
 
-	if err := tx.Rollback(); err != nil {
-		return fmt.Errorf("rollback transaction: %w", err)
+	if depth := savepointDepth(ctx); depth > 0 {
+		// The savepoint must be rolled back even if ctx has been canceled
+		_, err := tx.ExecContext(context.WithoutCancel(ctx), "ROLLBACK TO SAVEPOINT "+savepointName(depth))
+		if err != nil && !rolledBackOnDone(ctx, err) {
+			return fmt.Errorf("rollback to savepoint: %w", err)
+		}
+		return nil
 	}
 
+	if err := tx.Rollback(); err != nil && !rolledBackOnDone(ctx, err) {
+		return fmt.Errorf("rollback transaction: %w", toTxError(err))
+	}
 	return nil
 }
 
-// ExecTx executes a function within a transaction
-func (m *Manager) ExecTx(ctx context.Context, fn func(ctx context.Context) error) error {
-	tx, err := m.db.BeginTx(ctx, nil)
+// rolledBackOnDone reports whether err comes from a sql.Tx that database/sql has already rolled back
+// because ctx was canceled or its deadline expired, the rollback asked for being done then
+func rolledBackOnDone(ctx context.Context, err error) bool {
+	return ctx.Err() != nil && errors.Is(err, sql.ErrTxDone)
+}
+
+// beginTx starts a sql.Tx with the given options
+func (d *driver) beginTx(ctx context.Context, opts transaction.TxOptions) (*sql.Tx, error) {
+	tx, err := d.db.BeginTx(ctx, toSQLTxOptions(opts))
 	if err != nil {
-		return fmt.Errorf("begin transaction: %w", err)
+		return nil, fmt.Errorf("begin transaction: %w", err)
 	}
 
-	txCtx := context.WithValue(ctx, txKey{}, tx)
+	// database/sql has no notion of DEFERRABLE, so it is set as the first statement of the transaction
+	if opts.Deferrable {
+		if _, err := tx.ExecContext(ctx, "SET TRANSACTION DEFERRABLE"); err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("set transaction deferrable: %w", err)
+		}
+	}
 
-	if err := fn(txCtx); err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+	for _, stmt := range d.cfg.TimeoutsFor(opts).SetLocalStatements() {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("set transaction timeouts: %w", err)
 		}
-		return fmt.Errorf("transaction failed: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit transaction: %w", err)
+	return tx, nil
+}
+
+// savepoint creates a savepoint one level below the one carried by ctx
+func savepoint(ctx context.Context, tx *sql.Tx) (context.Context, error) {
+	depth := savepointDepth(ctx) + 1
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+savepointName(depth)); err != nil {
+		return nil, fmt.Errorf("create savepoint: %w", err)
 	}
 
-	return nil
+	return context.WithValue(ctx, savepointKey{}, depth), nil
+}
+
+// savepointDepth returns the nesting depth of the savepoint carried by ctx, 0 for the outermost transaction
+func savepointDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(savepointKey{}).(int)
+	return depth
+}
+
+// savepointName returns the name of the savepoint at the given depth
+func savepointName(depth int) string {
+	return fmt.Sprintf("sp_%d", depth)
 }
 
 // getTx extracts the sql.Tx from context
 func getTx(ctx context.Context) (*sql.Tx, error) {
-	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	tx, ok := TxFromContext(ctx)
 	if !ok {
-		return nil, errors.New("transaction not found in context")
+		return nil, transaction.ErrNoTransaction
 	}
 	return tx, nil
 }
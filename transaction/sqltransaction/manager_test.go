@@ -0,0 +1,415 @@
+package sqltransaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/transactiontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// newSQLiteDB opens a file-backed SQLite database with a single items table
+func newSQLiteDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Exec("CREATE TABLE items (name TEXT NOT NULL)")
+	require.NoError(t, err)
+	return db
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertItem inserts an item using the transaction carried by ctx, or db if there is none
+func insertItem(ctx context.Context, db *sql.DB, name string) error {
+	_, err := DBTX(ctx, db).ExecContext(ctx, "INSERT INTO items (name) VALUES (?)", name)
+	return err
+}
+
+// listItems returns the committed items
+func listItems(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+
+	rows, err := db.Query("SELECT name FROM items ORDER BY name")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	return names
+}
+
+func TestExecTx_Nested(t *testing.T) {
+	errInner := errors.New("inner failed")
+	errOuter := errors.New("outer failed")
+
+	tests := map[string]struct {
+		innerErr      error
+		outerErr      error
+		expectedError assert.ErrorAssertionFunc
+		expectedItems []string
+	}{
+		"success - inner unit is committed with the outer one": {
+			expectedError: assert.NoError,
+			expectedItems: []string{"inner", "outer"},
+		},
+		"success - inner failure only rolls back the inner unit": {
+			innerErr:      errInner,
+			expectedError: assert.NoError,
+			expectedItems: []string{"outer"},
+		},
+		"error - outer failure rolls back the released inner unit": {
+			outerErr:      errOuter,
+			expectedError: assert.Error,
+			expectedItems: []string{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := newSQLiteDB(t)
+			m := New(db)
+
+			err := m.ExecTx(context.Background(), func(ctx context.Context) error {
+				if err := insertItem(ctx, db, "outer"); err != nil {
+					return err
+				}
+
+				// The inner error is deliberately ignored: the outer unit decides on its own
+				_ = m.ExecTx(ctx, func(ctx context.Context) error {
+					if err := insertItem(ctx, db, "inner"); err != nil {
+						return err
+					}
+					return tt.innerErr
+				})
+
+				return tt.outerErr
+			})
+
+			tt.expectedError(t, err)
+			assert.Equal(t, tt.expectedItems, listItems(t, db))
+		})
+	}
+}
+
+func TestBegin_Nested(t *testing.T) {
+	db := newSQLiteDB(t)
+	m := New(db)
+
+	ctx, err := m.Begin(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, insertItem(ctx, db, "outer"))
+
+	for _, name := range []string{"first", "second"} {
+		spCtx, err := m.Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, insertItem(spCtx, db, name))
+		require.NoError(t, m.Rollback(spCtx))
+	}
+
+	spCtx, err := m.Begin(ctx)
+	require.NoError(t, err)
+	require.NoError(t, insertItem(spCtx, db, "third"))
+	require.NoError(t, m.Commit(spCtx))
+
+	require.NoError(t, m.Commit(ctx))
+	assert.Equal(t, []string{"outer", "third"}, listItems(t, db))
+}
+
+func TestExecTxWithOptions_Propagation(t *testing.T) {
+	errOuter := errors.New("outer failed")
+
+	tests := map[string]struct {
+		outer         bool
+		propagation   transaction.Propagation
+		expectedError error
+		expectedItems []string
+	}{
+		"nested - savepoint is rolled back with the outer transaction": {
+			outer:         true,
+			propagation:   transaction.PropagationNested,
+			expectedError: errOuter,
+			expectedItems: []string{},
+		},
+		"required - joined work is rolled back with the outer transaction": {
+			outer:         true,
+			propagation:   transaction.PropagationRequired,
+			expectedError: errOuter,
+			expectedItems: []string{},
+		},
+		"required - starts a transaction when there is none": {
+			propagation:   transaction.PropagationRequired,
+			expectedItems: []string{"inner"},
+		},
+		"requires new - inner transaction survives the outer rollback": {
+			outer:         true,
+			propagation:   transaction.PropagationRequiresNew,
+			expectedError: errOuter,
+			expectedItems: []string{"inner"},
+		},
+		"mandatory - joined work is rolled back with the outer transaction": {
+			outer:         true,
+			propagation:   transaction.PropagationMandatory,
+			expectedError: errOuter,
+			expectedItems: []string{},
+		},
+		"mandatory - fails without a transaction": {
+			propagation:   transaction.PropagationMandatory,
+			expectedError: transaction.ErrNoTransaction,
+			expectedItems: []string{},
+		},
+		"never - fails inside a transaction": {
+			outer:         true,
+			propagation:   transaction.PropagationNever,
+			expectedError: transaction.ErrTransactionExists,
+			expectedItems: []string{},
+		},
+		"never - writes directly without a transaction": {
+			propagation:   transaction.PropagationNever,
+			expectedItems: []string{"inner"},
+		},
+		"supported - joined work is rolled back with the outer transaction": {
+			outer:         true,
+			propagation:   transaction.PropagationSupported,
+			expectedError: errOuter,
+			expectedItems: []string{},
+		},
+		"supported - writes directly without a transaction": {
+			propagation:   transaction.PropagationSupported,
+			expectedItems: []string{"inner"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := newSQLiteDB(t)
+			m := New(db)
+
+			inner := func(ctx context.Context) error {
+				return m.ExecTxWithOptions(ctx, transaction.TxOptions{Propagation: tt.propagation}, func(ctx context.Context) error {
+					return insertItem(ctx, db, "inner")
+				})
+			}
+
+			var err error
+			if tt.outer {
+				err = m.ExecTx(context.Background(), func(ctx context.Context) error {
+					if err := inner(ctx); err != nil {
+						return err
+					}
+					// SQLite only allows one writer, so the outer transaction writes after the inner one
+					if err := insertItem(ctx, db, "outer"); err != nil {
+						return err
+					}
+					return errOuter
+				})
+			} else {
+				err = inner(context.Background())
+			}
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedItems, listItems(t, db))
+		})
+	}
+}
+
+func TestExecTx_Panic(t *testing.T) {
+	errPanic := errors.New("boom")
+
+	tests := map[string]struct {
+		opts []transaction.Option
+	}{
+		"re-panics after rolling back": {},
+		"returns a PanicError after rolling back": {
+			opts: []transaction.Option{transaction.WithPanicAsError()},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := newSQLiteDB(t)
+			m := New(db, tt.opts...)
+
+			fn := func(ctx context.Context) error {
+				if err := insertItem(ctx, db, "item"); err != nil {
+					return err
+				}
+				panic(errPanic)
+			}
+
+			if len(tt.opts) > 0 {
+				err := m.ExecTx(context.Background(), fn)
+
+				var panicErr *transaction.PanicError
+				assert.ErrorAs(t, err, &panicErr)
+				assert.ErrorIs(t, err, errPanic)
+			} else {
+				assert.PanicsWithValue(t, errPanic, func() {
+					_ = m.ExecTx(context.Background(), fn)
+				})
+			}
+
+			assert.Equal(t, []string{}, listItems(t, db))
+			assert.Zero(t, db.Stats().InUse, "the connection of the transaction must be released")
+		})
+	}
+}
+
+func TestExecTx_Hooks(t *testing.T) {
+	errVeto := errors.New("veto")
+
+	tests := map[string]struct {
+		beforeCommitErr error
+		innerErr        error
+		expectedError   error
+		expectedItems   []string
+		expectedEvents  []string
+	}{
+		"success - after-commit hooks see the committed rows": {
+			expectedItems: []string{"inner", "outer"},
+			expectedEvents: []string{
+				"before commit",
+				"outer committed: [inner outer]",
+				"inner committed: [inner outer]",
+			},
+		},
+		"error - before-commit hook vetoes the commit": {
+			beforeCommitErr: errVeto,
+			expectedError:   errVeto,
+			expectedItems:   []string{},
+			expectedEvents: []string{
+				"before commit",
+				"outer rolled back",
+				"inner rolled back",
+			},
+		},
+		"success - failed savepoint only runs its own rollback hooks": {
+			innerErr:      errors.New("inner failed"),
+			expectedItems: []string{"outer"},
+			expectedEvents: []string{
+				"inner rolled back",
+				"before commit",
+				"outer committed: [outer]",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := newSQLiteDB(t)
+			m := New(db)
+
+			var events []string
+			register := func(ctx context.Context, scope string) {
+				require.NoError(t, transaction.OnCommit(ctx, func(ctx context.Context) {
+					events = append(events, fmt.Sprintf("%s committed: %v", scope, listItems(t, db)))
+				}))
+				require.NoError(t, transaction.OnRollback(ctx, func(ctx context.Context) {
+					events = append(events, scope+" rolled back")
+				}))
+			}
+
+			err := m.ExecTx(context.Background(), func(ctx context.Context) error {
+				if err := insertItem(ctx, db, "outer"); err != nil {
+					return err
+				}
+				register(ctx, "outer")
+				require.NoError(t, transaction.BeforeCommit(ctx, func(ctx context.Context) error {
+					events = append(events, "before commit")
+					return tt.beforeCommitErr
+				}))
+
+				_ = m.ExecTx(ctx, func(ctx context.Context) error {
+					if err := insertItem(ctx, db, "inner"); err != nil {
+						return err
+					}
+					register(ctx, "inner")
+					return tt.innerErr
+				})
+				return nil
+			})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedItems, listItems(t, db))
+			assert.Equal(t, tt.expectedEvents, events)
+		})
+	}
+}
+
+func TestManager_Errors(t *testing.T) {
+	t.Run("commit without a transaction", func(t *testing.T) {
+		m := New(newSQLiteDB(t))
+
+		assert.ErrorIs(t, m.Commit(context.Background()), transaction.ErrNoTransaction)
+		assert.ErrorIs(t, m.Rollback(context.Background()), transaction.ErrNoTransaction)
+	})
+
+	t.Run("double commit", func(t *testing.T) {
+		m := New(newSQLiteDB(t))
+
+		ctx, err := m.Begin(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, m.Commit(ctx))
+
+		err = m.Commit(ctx)
+		var commitErr *transaction.CommitError
+		assert.ErrorAs(t, err, &commitErr)
+		assert.ErrorIs(t, err, transaction.ErrTxDone)
+		assert.ErrorIs(t, err, sql.ErrTxDone)
+
+		assert.ErrorIs(t, m.Rollback(ctx), transaction.ErrTxDone)
+	})
+
+	t.Run("failed rollback keeps both errors", func(t *testing.T) {
+		m := New(newSQLiteDB(t))
+		errFn := errors.New("fn failed")
+
+		err := m.ExecTx(context.Background(), func(ctx context.Context) error {
+			// Completing the transaction behind the manager's back makes its rollback fail
+			require.NoError(t, m.Commit(ctx))
+			return errFn
+		})
+
+		var rbErr *transaction.RollbackError
+		assert.ErrorAs(t, err, &rbErr)
+		assert.ErrorIs(t, err, errFn)
+		assert.ErrorIs(t, err, transaction.ErrTxDone)
+	})
+}
+
+func TestManagerSuite(t *testing.T) {
+	transactiontest.RunManagerSuite(t, func(t *testing.T) transactiontest.Harness {
+		db := newSQLiteDB(t)
+
+		return transactiontest.Harness{
+			Manager: New(db),
+			Insert: func(ctx context.Context, key string) error {
+				return insertItem(ctx, db, key)
+			},
+			Exists: func(ctx context.Context, key string) (bool, error) {
+				var exists bool
+				err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM items WHERE name = ?)", key).Scan(&exists)
+				return exists, err
+			},
+			WritableReadOnly: true,
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SQLSTATE codes of the errors reported when a PostgreSQL timeout expires
+const (
+	sqlStateQueryCanceled                   = "57014"
+	sqlStateLockNotAvailable                = "55P03"
+	sqlStateIdleInTransactionSessionTimeout = "25P03"
+)
+
+// ErrTimeout is returned when a transaction, one of its statements or one of its lock waits timed out
+// The error returned by the Manager wraps both ErrTimeout and the error that reported the timeout,
+// such as context.DeadlineExceeded or the driver error
+var ErrTimeout = errors.New("transaction timed out")
+
+// Timeouts bounds how long a transaction and its statements may run
+// A zero duration leaves the corresponding limit unset
+type Timeouts struct {
+	// Transaction is the wall-clock deadline of a transaction started by ExecTx, commit included
+	// The context given to the function is canceled when it expires
+	// Each attempt of a transaction run again by the retry policy gets a deadline of its own,
+	// so the whole call may take up to MaxAttempts times Transaction, backoff excluded
+	Transaction time.Duration
+
+	// Statement sets statement_timeout for the transaction (PostgreSQL only)
+	Statement time.Duration
+
+	// Lock sets lock_timeout for the transaction (PostgreSQL only)
+	Lock time.Duration
+
+	// IdleInTransaction sets idle_in_transaction_session_timeout for the transaction (PostgreSQL only)
+	// PostgreSQL closes the connection when it expires
+	IdleInTransaction time.Duration
+}
+
+// SetLocalStatements returns the SET LOCAL statements applying the PostgreSQL timeouts,
+// to be run first in the transaction
+func (t Timeouts) SetLocalStatements() []string {
+	var stmts []string
+	for _, setting := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"statement_timeout", t.Statement},
+		{"lock_timeout", t.Lock},
+		{"idle_in_transaction_session_timeout", t.IdleInTransaction},
+	} {
+		if setting.value <= 0 {
+			continue
+		}
+		// PostgreSQL takes milliseconds, 0 disabling the timeout
+		ms := max(setting.value.Milliseconds(), 1)
+		stmts = append(stmts, fmt.Sprintf("SET LOCAL %s = %d", setting.name, ms))
+	}
+	return stmts
+}
+
+// WithDeadline returns a copy of ctx canceled when the Transaction timeout expires, with ErrTimeout as its cause
+// ctx is returned as is when Transaction is not set
+func (t Timeouts) WithDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if t.Transaction <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, t.Transaction, ErrTimeout)
+}
+
+// IsTimeout reports whether err reports an expired statement_timeout or user cancellation (SQLSTATE 57014),
+// lock_timeout (SQLSTATE 55P03) or idle_in_transaction_session_timeout (SQLSTATE 25P03)
+// Like IsRetryable, it recognizes any error exposing its SQLSTATE through a SQLState() method
+// SQLSTATE 57014 also answers the cancel request pgx sends when a context is canceled;
+// WithTimeoutError tells both apart
+func IsTimeout(err error) bool {
+	var sqlErr interface{ SQLState() string }
+	if !errors.As(err, &sqlErr) {
+		return false
+	}
+
+	switch sqlErr.SQLState() {
+	case sqlStateQueryCanceled, sqlStateLockNotAvailable, sqlStateIdleInTransactionSessionTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// WithTimeoutError returns err wrapped with ErrTimeout when it is caused by a timeout:
+// either the deadline set on ctx by WithDeadline has expired, or IsTimeout(err) is true
+// Other errors, including the expiry of a deadline set by the caller, are returned as is;
+// so is the SQLSTATE 57014 error of a statement canceled because the caller canceled ctx or its deadline expired
+func WithTimeoutError(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, ErrTimeout) {
+		return err
+	}
+	if errors.Is(context.Cause(ctx), ErrTimeout) || IsTimeout(err) && !canceledByCaller(ctx, err) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+// canceledByCaller reports whether err is a canceled statement (SQLSTATE 57014) while ctx is done for a reason
+// other than the deadline of WithDeadline: the driver asked the server to cancel the statement because of ctx,
+// whereas an expired statement_timeout leaves ctx untouched
+func canceledByCaller(ctx context.Context, err error) bool {
+	var sqlErr interface{ SQLState() string }
+	return ctx.Err() != nil && errors.As(err, &sqlErr) && sqlErr.SQLState() == sqlStateQueryCanceled
+}
@@ -0,0 +1,152 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeouts_SetLocalStatements(t *testing.T) {
+	tests := map[string]struct {
+		timeouts Timeouts
+		expected []string
+	}{
+		"no timeout": {
+			timeouts: Timeouts{},
+			expected: nil,
+		},
+		"transaction deadline only": {
+			timeouts: Timeouts{Transaction: time.Second},
+			expected: nil,
+		},
+		"all settings": {
+			timeouts: Timeouts{Statement: 2 * time.Second, Lock: 500 * time.Millisecond, IdleInTransaction: time.Minute},
+			expected: []string{
+				"SET LOCAL statement_timeout = 2000",
+				"SET LOCAL lock_timeout = 500",
+				"SET LOCAL idle_in_transaction_session_timeout = 60000",
+			},
+		},
+		"sub-millisecond timeout is rounded up": {
+			timeouts: Timeouts{Lock: time.Microsecond},
+			expected: []string{"SET LOCAL lock_timeout = 1"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.timeouts.SetLocalStatements())
+		})
+	}
+}
+
+func TestIsTimeout(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"statement timeout":         {err: &sqlStateError{code: "57014"}, expected: true},
+		"lock timeout":              {err: &sqlStateError{code: "55P03"}, expected: true},
+		"idle in transaction":       {err: &sqlStateError{code: "25P03"}, expected: true},
+		"wrapped lock timeout":      {err: errors.Join(errors.New("update"), &sqlStateError{code: "55P03"}), expected: true},
+		"serialization failure":     {err: &sqlStateError{code: "40001"}, expected: false},
+		"error without sqlstate":    {err: errors.New("boom"), expected: false},
+		"context deadline exceeded": {err: context.DeadlineExceeded, expected: false},
+		"nil":                       {err: nil, expected: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsTimeout(tt.err))
+		})
+	}
+}
+
+func TestWithTimeoutError(t *testing.T) {
+	expired := func(cause error) context.Context {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(cause)
+		return ctx
+	}
+
+	tests := map[string]struct {
+		ctx             context.Context
+		err             error
+		expectedTimeout bool
+	}{
+		"transaction deadline expired": {
+			ctx:             expired(ErrTimeout),
+			err:             context.DeadlineExceeded,
+			expectedTimeout: true,
+		},
+		"lock timeout": {
+			ctx:             context.Background(),
+			err:             &sqlStateError{code: "55P03"},
+			expectedTimeout: true,
+		},
+		"deadline of the caller expired": {
+			ctx:             expired(context.DeadlineExceeded),
+			err:             context.DeadlineExceeded,
+			expectedTimeout: false,
+		},
+		"statement timeout": {
+			ctx:             context.Background(),
+			err:             &sqlStateError{code: "57014"},
+			expectedTimeout: true,
+		},
+		"statement canceled by the transaction deadline": {
+			ctx:             expired(ErrTimeout),
+			err:             &sqlStateError{code: "57014"},
+			expectedTimeout: true,
+		},
+		"statement canceled with the context of the caller": {
+			ctx:             expired(context.Canceled),
+			err:             &sqlStateError{code: "57014"},
+			expectedTimeout: false,
+		},
+		"statement canceled by the deadline of the caller": {
+			ctx:             expired(context.DeadlineExceeded),
+			err:             &sqlStateError{code: "57014"},
+			expectedTimeout: false,
+		},
+		"lock timeout while the caller cancels": {
+			ctx:             expired(context.Canceled),
+			err:             &sqlStateError{code: "55P03"},
+			expectedTimeout: true,
+		},
+		"other error": {
+			ctx:             context.Background(),
+			err:             errors.New("boom"),
+			expectedTimeout: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := WithTimeoutError(tt.ctx, tt.err)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expectedTimeout, errors.Is(err, ErrTimeout))
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		assert.NoError(t, WithTimeoutError(expired(ErrTimeout), nil))
+	})
+}
+
+func TestTimeouts_WithDeadline(t *testing.T) {
+	ctx, cancel := Timeouts{}.WithDeadline(context.Background())
+	defer cancel()
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+
+	ctx, cancel = Timeouts{Transaction: time.Millisecond}.WithDeadline(context.Background())
+	defer cancel()
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+	assert.ErrorIs(t, context.Cause(ctx), ErrTimeout)
+}
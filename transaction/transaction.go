@@ -10,7 +10,10 @@ import (
 // This interface allows for implementation across different database drivers
 type Manager interface {
 	Begin(ctx context.Context) (context.Context, error)
+	BeginWithOptions(ctx context.Context, opts TxOptions) (context.Context, error)
 	Commit(ctx context.Context) error
 	Rollback(ctx context.Context) error
 	ExecTx(ctx context.Context, fn func(ctx context.Context) error) error
+	ExecTxWithOptions(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error
+	ExecReadOnly(ctx context.Context, fn func(ctx context.Context) error) error
 }
@@ -0,0 +1,575 @@
+// Package transactiontest provides a conformance test suite for transaction.Manager implementations
+package transactiontest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Harness gives the suite access to a Manager and to a table of keys it can write through it
+type Harness struct {
+	// Manager is the implementation under test
+	Manager transaction.Manager
+
+	// Insert stores key using the transaction carried by ctx, or outside any transaction if there is none
+	Insert func(ctx context.Context, key string) error
+
+	// Exists reports whether key has been committed
+	Exists func(ctx context.Context, key string) (bool, error)
+
+	// SingleWriter tells that the database allows a single write transaction at a time, like SQLite
+	// The cases running a write transaction inside another one are skipped
+	SingleWriter bool
+
+	// WritableReadOnly tells that the database accepts writes in read-only transactions, like SQLite
+	// The cases checking that such writes fail are skipped
+	WritableReadOnly bool
+}
+
+// Factory returns a Harness backed by an empty table
+// It is called once per test case and should register its cleanup with t.Cleanup
+type Factory func(t *testing.T) Harness
+
+// RunManagerSuite checks that the Manager returned by factory behaves like the reference implementations
+func RunManagerSuite(t *testing.T, factory Factory) {
+	t.Run("ExecTx", func(t *testing.T) { testExecTx(t, factory) })
+	t.Run("ManualTransaction", func(t *testing.T) { testManualTransaction(t, factory) })
+	t.Run("Errors", func(t *testing.T) { testErrors(t, factory) })
+	t.Run("ContextCancellation", func(t *testing.T) { testContextCancellation(t, factory) })
+	t.Run("Nesting", func(t *testing.T) { testNesting(t, factory) })
+	t.Run("Propagation", func(t *testing.T) { testPropagation(t, factory) })
+	t.Run("Hooks", func(t *testing.T) { testHooks(t, factory) })
+	t.Run("Panic", func(t *testing.T) { testPanic(t, factory) })
+	t.Run("ReadOnly", func(t *testing.T) { testReadOnly(t, factory) })
+	t.Run("Timeout", func(t *testing.T) { testTimeout(t, factory) })
+}
+
+var errFn = errors.New("fn failed")
+
+// assertKeys checks which of the keys have been committed
+func assertKeys(t *testing.T, h Harness, committed []string, rolledBack []string) {
+	t.Helper()
+
+	for _, key := range committed {
+		ok, err := h.Exists(context.Background(), key)
+		require.NoError(t, err)
+		assert.True(t, ok, "%q should have been committed", key)
+	}
+	for _, key := range rolledBack {
+		ok, err := h.Exists(context.Background(), key)
+		require.NoError(t, err)
+		assert.False(t, ok, "%q should have been rolled back", key)
+	}
+}
+
+func testExecTx(t *testing.T, factory Factory) {
+	t.Run("commits when fn succeeds", func(t *testing.T) {
+		h := factory(t)
+
+		err := h.Manager.ExecTx(context.Background(), func(ctx context.Context) error {
+			return h.Insert(ctx, "a")
+		})
+
+		assert.NoError(t, err)
+		assertKeys(t, h, []string{"a"}, nil)
+	})
+
+	t.Run("rolls back when fn fails", func(t *testing.T) {
+		h := factory(t)
+
+		err := h.Manager.ExecTx(context.Background(), func(ctx context.Context) error {
+			if err := h.Insert(ctx, "a"); err != nil {
+				return err
+			}
+			return errFn
+		})
+
+		assert.ErrorIs(t, err, errFn)
+		assertKeys(t, h, nil, []string{"a"})
+	})
+
+	t.Run("accepts options", func(t *testing.T) {
+		h := factory(t)
+
+		err := h.Manager.ExecTxWithOptions(context.Background(), transaction.TxOptions{
+			Isolation: transaction.LevelSerializable,
+		}, func(ctx context.Context) error {
+			return h.Insert(ctx, "a")
+		})
+
+		assert.NoError(t, err)
+		assertKeys(t, h, []string{"a"}, nil)
+	})
+}
+
+func testManualTransaction(t *testing.T, factory Factory) {
+	t.Run("Begin and Commit", func(t *testing.T) {
+		h := factory(t)
+
+		ctx, err := h.Manager.Begin(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, h.Insert(ctx, "a"))
+		require.NoError(t, h.Manager.Commit(ctx))
+
+		assertKeys(t, h, []string{"a"}, nil)
+	})
+
+	t.Run("Begin and Rollback", func(t *testing.T) {
+		h := factory(t)
+
+		ctx, err := h.Manager.Begin(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, h.Insert(ctx, "a"))
+		require.NoError(t, h.Manager.Rollback(ctx))
+
+		assertKeys(t, h, nil, []string{"a"})
+	})
+
+	t.Run("nested Begin creates a savepoint", func(t *testing.T) {
+		h := factory(t)
+
+		ctx, err := h.Manager.Begin(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, h.Insert(ctx, "outer"))
+
+		spCtx, err := h.Manager.Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, h.Insert(spCtx, "rolled back"))
+		require.NoError(t, h.Manager.Rollback(spCtx))
+
+		spCtx, err = h.Manager.Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, h.Insert(spCtx, "released"))
+		require.NoError(t, h.Manager.Commit(spCtx))
+
+		require.NoError(t, h.Manager.Commit(ctx))
+		assertKeys(t, h, []string{"outer", "released"}, []string{"rolled back"})
+	})
+}
+
+func testErrors(t *testing.T, factory Factory) {
+	t.Run("Commit and Rollback without a transaction", func(t *testing.T) {
+		h := factory(t)
+
+		assert.ErrorIs(t, h.Manager.Commit(context.Background()), transaction.ErrNoTransaction)
+		assert.ErrorIs(t, h.Manager.Rollback(context.Background()), transaction.ErrNoTransaction)
+	})
+
+	t.Run("double Commit", func(t *testing.T) {
+		h := factory(t)
+
+		ctx, err := h.Manager.Begin(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, h.Manager.Commit(ctx))
+
+		err = h.Manager.Commit(ctx)
+		var commitErr *transaction.CommitError
+		assert.ErrorAs(t, err, &commitErr)
+		assert.ErrorIs(t, err, transaction.ErrTxDone)
+	})
+
+	t.Run("Rollback after Commit", func(t *testing.T) {
+		h := factory(t)
+
+		ctx, err := h.Manager.Begin(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, h.Manager.Commit(ctx))
+
+		assert.ErrorIs(t, h.Manager.Rollback(ctx), transaction.ErrTxDone)
+	})
+
+	t.Run("failed rollback keeps both errors", func(t *testing.T) {
+		h := factory(t)
+
+		err := h.Manager.ExecTx(context.Background(), func(ctx context.Context) error {
+			// Completing the transaction behind the manager's back makes its rollback fail
+			require.NoError(t, h.Manager.Commit(ctx))
+			return errFn
+		})
+
+		var rbErr *transaction.RollbackError
+		assert.ErrorAs(t, err, &rbErr)
+		assert.ErrorIs(t, err, errFn)
+		assert.ErrorIs(t, err, transaction.ErrTxDone)
+	})
+}
+
+func testContextCancellation(t *testing.T, factory Factory) {
+	t.Run("canceled before the transaction starts", func(t *testing.T) {
+		h := factory(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		called := false
+		err := h.Manager.ExecTx(ctx, func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, called)
+	})
+
+	t.Run("canceled while the transaction runs", func(t *testing.T) {
+		h := factory(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		err := h.Manager.ExecTx(ctx, func(ctx context.Context) error {
+			if err := h.Insert(ctx, "a"); err != nil {
+				return err
+			}
+			cancel()
+			return nil
+		})
+
+		assert.Error(t, err)
+		assertKeys(t, h, nil, []string{"a"})
+	})
+}
+
+func testNesting(t *testing.T, factory Factory) {
+	tests := map[string]struct {
+		innerErr   error
+		outerErr   error
+		committed  []string
+		rolledBack []string
+	}{
+		"inner unit is committed with the outer one": {
+			committed: []string{"outer", "inner"},
+		},
+		"inner failure only rolls back the inner unit": {
+			innerErr:   errFn,
+			committed:  []string{"outer"},
+			rolledBack: []string{"inner"},
+		},
+		"outer failure rolls back the released inner unit": {
+			outerErr:   errFn,
+			rolledBack: []string{"outer", "inner"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := factory(t)
+
+			err := h.Manager.ExecTx(context.Background(), func(ctx context.Context) error {
+				if err := h.Insert(ctx, "outer"); err != nil {
+					return err
+				}
+
+				innerErr := h.Manager.ExecTx(ctx, func(ctx context.Context) error {
+					if err := h.Insert(ctx, "inner"); err != nil {
+						return err
+					}
+					return tt.innerErr
+				})
+				assert.ErrorIs(t, innerErr, tt.innerErr)
+
+				return tt.outerErr
+			})
+
+			assert.ErrorIs(t, err, tt.outerErr)
+			assertKeys(t, h, tt.committed, tt.rolledBack)
+		})
+	}
+}
+
+func testPropagation(t *testing.T, factory Factory) {
+	tests := map[string]struct {
+		outer         bool
+		propagation   transaction.Propagation
+		expectedError error
+		committed     []string
+		rolledBack    []string
+		twoWriters    bool
+	}{
+		"nested - savepoint is rolled back with the outer transaction": {
+			outer:         true,
+			propagation:   transaction.PropagationNested,
+			expectedError: errFn,
+			rolledBack:    []string{"inner", "outer"},
+		},
+		"required - joined work is rolled back with the outer transaction": {
+			outer:         true,
+			propagation:   transaction.PropagationRequired,
+			expectedError: errFn,
+			rolledBack:    []string{"inner", "outer"},
+		},
+		"required - starts a transaction when there is none": {
+			propagation: transaction.PropagationRequired,
+			committed:   []string{"inner"},
+		},
+		"requires new - inner transaction survives the outer rollback": {
+			outer:         true,
+			propagation:   transaction.PropagationRequiresNew,
+			expectedError: errFn,
+			committed:     []string{"inner"},
+			rolledBack:    []string{"outer"},
+			twoWriters:    true,
+		},
+		"mandatory - joined work is rolled back with the outer transaction": {
+			outer:         true,
+			propagation:   transaction.PropagationMandatory,
+			expectedError: errFn,
+			rolledBack:    []string{"inner", "outer"},
+		},
+		"mandatory - fails without a transaction": {
+			propagation:   transaction.PropagationMandatory,
+			expectedError: transaction.ErrNoTransaction,
+			rolledBack:    []string{"inner"},
+		},
+		"never - fails inside a transaction": {
+			outer:         true,
+			propagation:   transaction.PropagationNever,
+			expectedError: transaction.ErrTransactionExists,
+			rolledBack:    []string{"inner", "outer"},
+		},
+		"never - writes directly without a transaction": {
+			propagation: transaction.PropagationNever,
+			committed:   []string{"inner"},
+		},
+		"supported - joined work is rolled back with the outer transaction": {
+			outer:         true,
+			propagation:   transaction.PropagationSupported,
+			expectedError: errFn,
+			rolledBack:    []string{"inner", "outer"},
+		},
+		"supported - writes directly without a transaction": {
+			propagation: transaction.PropagationSupported,
+			committed:   []string{"inner"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := factory(t)
+			if tt.twoWriters && h.SingleWriter {
+				t.Skip("the database allows a single write transaction at a time")
+			}
+
+			inner := func(ctx context.Context) error {
+				return h.Manager.ExecTxWithOptions(ctx, transaction.TxOptions{Propagation: tt.propagation}, func(ctx context.Context) error {
+					return h.Insert(ctx, "inner")
+				})
+			}
+
+			var err error
+			if tt.outer {
+				err = h.Manager.ExecTx(context.Background(), func(ctx context.Context) error {
+					if err := inner(ctx); err != nil {
+						return err
+					}
+					// The outer transaction writes last, so that single-writer databases such as SQLite
+					// do not block the inner transaction of PropagationRequiresNew
+					if err := h.Insert(ctx, "outer"); err != nil {
+						return err
+					}
+					return errFn
+				})
+			} else {
+				err = inner(context.Background())
+			}
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assertKeys(t, h, tt.committed, tt.rolledBack)
+		})
+	}
+}
+
+func testHooks(t *testing.T, factory Factory) {
+	tests := map[string]struct {
+		fnErr          error
+		expectedEvents []string
+	}{
+		"commit runs the before-commit and after-commit hooks": {
+			expectedEvents: []string{"before commit", "after commit: true"},
+		},
+		"rollback runs the after-rollback hooks": {
+			fnErr:          errFn,
+			expectedEvents: []string{"after rollback: false"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := factory(t)
+
+			var events []string
+			record := func(event string) func(ctx context.Context) {
+				return func(ctx context.Context) {
+					ok, err := h.Exists(ctx, "a")
+					require.NoError(t, err)
+					events = append(events, fmt.Sprintf("%s: %t", event, ok))
+				}
+			}
+
+			err := h.Manager.ExecTx(context.Background(), func(ctx context.Context) error {
+				if err := h.Insert(ctx, "a"); err != nil {
+					return err
+				}
+				require.NoError(t, transaction.BeforeCommit(ctx, func(ctx context.Context) error {
+					events = append(events, "before commit")
+					return nil
+				}))
+				require.NoError(t, transaction.OnCommit(ctx, record("after commit")))
+				require.NoError(t, transaction.OnRollback(ctx, record("after rollback")))
+				return tt.fnErr
+			})
+
+			assert.ErrorIs(t, err, tt.fnErr)
+			assert.Equal(t, tt.expectedEvents, events)
+		})
+	}
+}
+
+func testPanic(t *testing.T, factory Factory) {
+	panicInFn := func(ctx context.Context) error {
+		panic("boom")
+	}
+	panicBeforeCommit := func(ctx context.Context) error {
+		return transaction.BeforeCommit(ctx, func(ctx context.Context) error {
+			panic("boom")
+		})
+	}
+
+	tests := map[string]struct {
+		run func(h Harness, fn func(ctx context.Context) error)
+		fn  func(ctx context.Context) error
+	}{
+		"fn panics": {
+			run: execTx,
+			fn:  panicInFn,
+		},
+		"before-commit hook panics": {
+			run: execTx,
+			fn:  panicBeforeCommit,
+		},
+		"before-commit hook panics in Commit": {
+			run: func(h Harness, fn func(ctx context.Context) error) {
+				ctx, err := h.Manager.Begin(context.Background())
+				require.NoError(t, err)
+				require.NoError(t, fn(ctx))
+				_ = h.Manager.Commit(ctx)
+			},
+			fn: panicBeforeCommit,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := factory(t)
+
+			rolledBack := false
+			assert.PanicsWithValue(t, "boom", func() {
+				tt.run(h, func(ctx context.Context) error {
+					if err := h.Insert(ctx, "a"); err != nil {
+						return err
+					}
+					require.NoError(t, transaction.OnRollback(ctx, func(ctx context.Context) {
+						rolledBack = true
+					}))
+					return tt.fn(ctx)
+				})
+			})
+
+			assert.True(t, rolledBack)
+			assertKeys(t, h, nil, []string{"a"})
+
+			// The transaction must not be leaked, e.g. keeping its connection or locks
+			require.NoError(t, h.Manager.ExecTx(context.Background(), func(ctx context.Context) error {
+				return h.Insert(ctx, "b")
+			}))
+			assertKeys(t, h, []string{"b"}, nil)
+		})
+	}
+}
+
+// execTx runs fn with ExecTx, ignoring its error
+func execTx(h Harness, fn func(ctx context.Context) error) {
+	_ = h.Manager.ExecTx(context.Background(), fn)
+}
+
+func testReadOnly(t *testing.T, factory Factory) {
+	t.Run("returns the error of fn", func(t *testing.T) {
+		h := factory(t)
+
+		err := h.Manager.ExecReadOnly(context.Background(), func(ctx context.Context) error {
+			return errFn
+		})
+
+		assert.ErrorIs(t, err, errFn)
+	})
+
+	t.Run("rejects writes", func(t *testing.T) {
+		h := factory(t)
+		if h.WritableReadOnly {
+			t.Skip("the database accepts writes in read-only transactions")
+		}
+
+		err := h.Manager.ExecReadOnly(context.Background(), func(ctx context.Context) error {
+			return h.Insert(ctx, "a")
+		})
+
+		assert.Error(t, err)
+		assertKeys(t, h, nil, []string{"a"})
+	})
+
+	t.Run("joins the current transaction", func(t *testing.T) {
+		h := factory(t)
+
+		err := h.Manager.ExecTx(context.Background(), func(ctx context.Context) error {
+			return h.Manager.ExecReadOnly(ctx, func(ctx context.Context) error {
+				// The joined transaction is read-write
+				return h.Insert(ctx, "a")
+			})
+		})
+
+		assert.NoError(t, err)
+		assertKeys(t, h, []string{"a"}, nil)
+	})
+}
+
+func testTimeout(t *testing.T, factory Factory) {
+	t.Run("transaction deadline rolls back", func(t *testing.T) {
+		h := factory(t)
+		opts := transaction.TxOptions{Timeouts: &transaction.Timeouts{Transaction: 50 * time.Millisecond}}
+
+		err := h.Manager.ExecTxWithOptions(context.Background(), opts, func(ctx context.Context) error {
+			if err := h.Insert(ctx, "a"); err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		assert.ErrorIs(t, err, transaction.ErrTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		// Rolling back after the deadline is not a failed rollback
+		var rbErr *transaction.RollbackError
+		assert.False(t, errors.As(err, &rbErr), "unexpected rollback error: %v", err)
+		assertKeys(t, h, nil, []string{"a"})
+	})
+
+	t.Run("deadline of the caller is not a transaction timeout", func(t *testing.T) {
+		h := factory(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		opts := transaction.TxOptions{Timeouts: &transaction.Timeouts{Transaction: time.Minute}}
+
+		err := h.Manager.ExecTxWithOptions(ctx, opts, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, transaction.ErrTimeout)
+	})
+}
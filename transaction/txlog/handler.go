@@ -0,0 +1,54 @@
+package txlog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler decorates a slog.Handler with the ID of the transaction carried by the context of each record,
+// so that the records logged by the stores can be correlated with the transaction they ran in
+type Handler struct {
+	next slog.Handler
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler creates a new Handler adding the transaction ID to the records handled by next
+// Only the records logged with a context, e.g. with slog.InfoContext, carry it
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+// Enabled implements slog.Handler
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if id, ok := IDFromContext(ctx); ok && !hasTxID(r) {
+		r = r.Clone()
+		r.AddAttrs(slog.String(KeyTxID, id))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+// hasTxID reports whether r already carries the transaction ID, like the records of Middleware
+func hasTxID(r slog.Record) bool {
+	found := false
+	r.Attrs(func(a slog.Attr) bool {
+		found = a.Key == KeyTxID
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,46 @@
+package txlog
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	tests := map[string]struct {
+		ctx           context.Context
+		attrs         []any
+		expectedAttrs map[string]string
+	}{
+		"success - adds the transaction ID": {
+			ctx:           context.WithValue(context.Background(), idKey{}, "tx-1"),
+			attrs:         []any{"user_id", "42"},
+			expectedAttrs: map[string]string{"user_id": "42", KeyTxID: "tx-1"},
+		},
+		"success - keeps the transaction ID of the record": {
+			ctx:           context.WithValue(context.Background(), idKey{}, "tx-1"),
+			attrs:         []any{KeyTxID, "tx-0"},
+			expectedAttrs: map[string]string{KeyTxID: "tx-0"},
+		},
+		"success - outside of a transaction": {
+			ctx:           context.Background(),
+			attrs:         []any{"user_id", "42"},
+			expectedAttrs: map[string]string{"user_id": "42"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			capture := &captureHandler{}
+			logger := slog.New(NewHandler(capture))
+
+			logger.InfoContext(tt.ctx, "user created", tt.attrs...)
+
+			require.Len(t, capture.records, 1)
+			assert.Equal(t, tt.expectedAttrs, capture.records[0].attrs)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+// Package txlog logs the transactions of a transaction.Manager with log/slog
+package txlog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/google/uuid"
+)
+
+// Attributes of the log records
+const (
+	KeyTxID      = "tx_id"
+	KeyName      = "tx_name"
+	KeyIsolation = "isolation"
+	KeyReadOnly  = "read_only"
+	KeyDuration  = "duration"
+	KeyAttempts  = "attempts"
+	KeyReason    = "reason"
+	KeyError     = "error"
+	KeySlow      = "slow"
+)
+
+// Values of KeyReason
+const (
+	ReasonError        = "error"
+	ReasonPanic        = "panic"
+	ReasonTimeout      = "timeout"
+	ReasonCommitFailed = "commit_failed"
+	ReasonExplicit     = "explicit"
+)
+
+// idKey is a key for retrieving the ID of the current transaction from context
+type idKey struct{}
+
+// state is what is needed to log the end of a transaction
+type state struct {
+	id      string
+	start   time.Time
+	sampled bool
+}
+
+// IDFromContext returns the ID of the transaction carried by ctx, if it was started through Middleware
+func IDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(idKey{}).(string)
+	return id, ok
+}
+
+// Option configures the logging of Middleware
+type Option func(*txLogger)
+
+// WithSampleRate logs the begin and commit of only a fraction of the transactions, between 0 and 1
+// Rollbacks and slow transactions are always logged
+func WithSampleRate(rate float64) Option {
+	return func(l *txLogger) {
+		l.sample = func() bool { return rand.Float64() < rate }
+	}
+}
+
+// WithSlowThreshold logs the transactions lasting at least d as slow, at the warning level
+func WithSlowThreshold(d time.Duration) Option {
+	return func(l *txLogger) {
+		l.slowThreshold = d
+	}
+}
+
+// New returns a Manager logging the transactions of next with logger, or with slog.Default() if logger is nil,
+// see Middleware
+func New(next transaction.Manager, logger *slog.Logger, opts ...Option) transaction.Manager {
+	return transaction.Chain(next, Middleware(logger, opts...))
+}
+
+// Middleware returns a transaction.Middleware logging the transactions run through transaction.Chain
+// with a record per begin, commit and rollback, with logger or with slog.Default() if logger is nil
+//
+// Each new transaction gets an ID, carried by the context given to the function or returned by Begin,
+// so that the records logged in the transaction can be correlated with NewHandler
+// Begins are logged at the debug level, commits at the info level and rollbacks at the warning level
+// Savepoints and the units of work joining a transaction are part of the transaction they run in
+func Middleware(logger *slog.Logger, opts ...Option) transaction.Middleware {
+	return newTxLogger(logger, opts...).middleware()
+}
+
+// txLogger logs transactions with logger
+type txLogger struct {
+	logger        *slog.Logger
+	slowThreshold time.Duration
+	sample        func() bool
+	newID         func() string
+	now           func() time.Time
+}
+
+// newTxLogger creates a new txLogger logging with logger, or with slog.Default() if logger is nil
+func newTxLogger(logger *slog.Logger, opts ...Option) *txLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	l := &txLogger{
+		logger: logger,
+		sample: func() bool { return true },
+		newID:  uuid.NewString,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// middleware returns a transaction.Middleware logging the transactions with the logger and options of l
+func (l *txLogger) middleware() transaction.Middleware {
+	return func(next transaction.ExecFunc) transaction.ExecFunc {
+		return func(ctx context.Context, opts transaction.TxOptions, fn func(ctx context.Context) error) error {
+			if !transaction.StartsTransaction(ctx, opts) {
+				return next(ctx, opts, fn)
+			}
+			return l.exec(ctx, opts, fn, next)
+		}
+	}
+}
+
+// exec runs fn in a new transaction with next and logs it
+func (l *txLogger) exec(ctx context.Context, opts transaction.TxOptions, fn func(ctx context.Context) error, next transaction.ExecFunc) error {
+	s := l.start(ctx, opts)
+	ctx = context.WithValue(ctx, idKey{}, s.id)
+
+	attempts := 0
+	done := false
+	defer func() {
+		if !done {
+			// fn panicked: the panic goes on unchanged, the transaction having been rolled back
+			l.rollback(ctx, s, attempts, ReasonPanic, nil)
+		}
+	}()
+
+	err := next(ctx, opts, func(ctx context.Context) error {
+		attempts++
+		return fn(ctx)
+	})
+	done = true
+
+	var rollbackErr *transaction.RollbackError
+	switch {
+	case errors.Is(err, transaction.ErrRolledBack) && !errors.As(err, &rollbackErr):
+		// Rollback was called: the transaction did not fail
+		l.rollback(ctx, s, attempts, ReasonExplicit, nil)
+	case err != nil:
+		l.rollback(ctx, s, attempts, reason(err), err)
+	default:
+		l.commit(ctx, s, attempts)
+	}
+	return err
+}
+
+// start creates the state of a new transaction and logs its begin if it is sampled
+func (l *txLogger) start(ctx context.Context, opts transaction.TxOptions) *state {
+	s := &state{
+		id:      l.newID(),
+		start:   l.now(),
+		sampled: l.sample(),
+	}
+	if s.sampled {
+		l.logger.LogAttrs(ctx, slog.LevelDebug, "transaction begin",
+			slog.String(KeyTxID, s.id),
+			slog.String(KeyName, transaction.NameFromContext(ctx)),
+			slog.String(KeyIsolation, opts.Isolation.String()),
+			slog.Bool(KeyReadOnly, opts.ReadOnly),
+		)
+	}
+	return s
+}
+
+// commit logs the commit of a transaction if it is sampled or slow
+func (l *txLogger) commit(ctx context.Context, s *state, attempts int) {
+	duration := l.now().Sub(s.start)
+	slow := l.slowThreshold > 0 && duration >= l.slowThreshold
+	if !s.sampled && !slow {
+		return
+	}
+
+	level := slog.LevelInfo
+	if slow {
+		level = slog.LevelWarn
+	}
+	l.logger.LogAttrs(ctx, level, "transaction commit", l.endAttrs(ctx, s, duration, attempts, slow)...)
+}
+
+// rollback logs the rollback of a transaction, with its reason and the error causing it, if any
+func (l *txLogger) rollback(ctx context.Context, s *state, attempts int, reason string, err error) {
+	duration := l.now().Sub(s.start)
+	slow := l.slowThreshold > 0 && duration >= l.slowThreshold
+
+	attrs := append(l.endAttrs(ctx, s, duration, attempts, slow), slog.String(KeyReason, reason))
+	if err != nil {
+		attrs = append(attrs, slog.String(KeyError, err.Error()))
+	}
+	l.logger.LogAttrs(ctx, slog.LevelWarn, "transaction rollback", attrs...)
+}
+
+// endAttrs returns the attributes shared by the commit and rollback records
+func (l *txLogger) endAttrs(ctx context.Context, s *state, duration time.Duration, attempts int, slow bool) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String(KeyTxID, s.id),
+		slog.String(KeyName, transaction.NameFromContext(ctx)),
+		slog.Duration(KeyDuration, duration),
+	}
+	if attempts > 0 {
+		attrs = append(attrs, slog.Int(KeyAttempts, attempts))
+	}
+	if slow {
+		attrs = append(attrs, slog.Bool(KeySlow, true))
+	}
+	return attrs
+}
+
+// reason returns why a transaction whose function or commit returned err was rolled back
+func reason(err error) string {
+	var panicErr *transaction.PanicError
+	var commitErr *transaction.CommitError
+	switch {
+	case errors.As(err, &panicErr):
+		return ReasonPanic
+	case errors.Is(err, transaction.ErrTimeout):
+		return ReasonTimeout
+	case errors.As(err, &commitErr):
+		return ReasonCommitFailed
+	case errors.Is(err, transaction.ErrRolledBack):
+		return ReasonExplicit
+	default:
+		return ReasonError
+	}
+}
@@ -0,0 +1,256 @@
+package txlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/memtransaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errFn = errors.New("fn failed")
+
+// record is a log record with its attributes formatted as strings
+type record struct {
+	level   slog.Level
+	message string
+	attrs   map[string]string
+}
+
+// captureHandler is a slog.Handler keeping the records it handles
+type captureHandler struct {
+	mu      sync.Mutex
+	records []record
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	rec := record{level: r.Level, message: r.Message, attrs: map[string]string{}}
+	r.Attrs(func(a slog.Attr) bool {
+		rec.attrs[a.Key] = a.Value.String()
+		return true
+	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, rec)
+	return nil
+}
+
+func (h *captureHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *captureHandler) WithGroup(string) slog.Handler { return h }
+
+// newManager returns a Manager on top of memtransaction whose clock moves one second per reading
+// and whose transaction IDs are tx-1, tx-2, ...
+func newManager(handler slog.Handler, opts []transaction.Option, logOpts ...Option) transaction.Manager {
+	l := newTxLogger(slog.New(handler), logOpts...)
+	var now time.Time
+	l.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	ids := 0
+	l.newID = func() string {
+		ids++
+		return fmt.Sprintf("tx-%d", ids)
+	}
+	return transaction.Chain(memtransaction.New(opts...), l.middleware())
+}
+
+func TestManager_Exec(t *testing.T) {
+	tests := map[string]struct {
+		opts            []transaction.Option
+		logOpts         []Option
+		run             func(ctx context.Context, m transaction.Manager) error
+		expectedRecords []record
+	}{
+		"success - committed transaction": {
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(transaction.WithName(ctx, "create_user"), func(ctx context.Context) error { return nil })
+			},
+			expectedRecords: []record{
+				{level: slog.LevelDebug, message: "transaction begin", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "create_user", KeyIsolation: "DEFAULT", KeyReadOnly: "false",
+				}},
+				{level: slog.LevelInfo, message: "transaction commit", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "create_user", KeyDuration: "1s", KeyAttempts: "1",
+				}},
+			},
+		},
+		"success - savepoints and joined units are part of the transaction": {
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error {
+					if err := m.ExecTx(ctx, func(ctx context.Context) error { return nil }); err != nil {
+						return err
+					}
+					return m.ExecReadOnly(ctx, func(ctx context.Context) error { return nil })
+				})
+			},
+			expectedRecords: []record{
+				{level: slog.LevelDebug, message: "transaction begin", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "", KeyIsolation: "DEFAULT", KeyReadOnly: "false",
+				}},
+				{level: slog.LevelInfo, message: "transaction commit", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "", KeyDuration: "1s", KeyAttempts: "1",
+				}},
+			},
+		},
+		"success - unsampled transaction is not logged": {
+			logOpts: []Option{WithSampleRate(0)},
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error { return nil })
+			},
+			expectedRecords: nil,
+		},
+		"success - slow transaction is logged despite sampling": {
+			logOpts: []Option{WithSampleRate(0), WithSlowThreshold(time.Second)},
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecReadOnly(ctx, func(ctx context.Context) error { return nil })
+			},
+			expectedRecords: []record{
+				{level: slog.LevelWarn, message: "transaction commit", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "", KeyDuration: "1s", KeyAttempts: "1", KeySlow: "true",
+				}},
+			},
+		},
+		"error - rollback is logged despite sampling": {
+			logOpts: []Option{WithSampleRate(0)},
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error { return errFn })
+			},
+			expectedRecords: []record{
+				{level: slog.LevelWarn, message: "transaction rollback", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "", KeyDuration: "1s", KeyAttempts: "1",
+					KeyReason: ReasonError, KeyError: "transaction failed: fn failed",
+				}},
+			},
+		},
+		"error - timeout": {
+			logOpts: []Option{WithSampleRate(0)},
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error {
+					return fmt.Errorf("%w: %w", transaction.ErrTimeout, context.DeadlineExceeded)
+				})
+			},
+			expectedRecords: []record{
+				{level: slog.LevelWarn, message: "transaction rollback", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "", KeyDuration: "1s", KeyAttempts: "1",
+					KeyReason: ReasonTimeout, KeyError: "transaction failed: transaction timed out: context deadline exceeded",
+				}},
+			},
+		},
+		"error - commit failed": {
+			logOpts: []Option{WithSampleRate(0)},
+			run: func(ctx context.Context, m transaction.Manager) error {
+				ctx, cancel := context.WithCancel(ctx)
+				return m.ExecTx(ctx, func(ctx context.Context) error {
+					cancel()
+					return nil
+				})
+			},
+			expectedRecords: []record{
+				{level: slog.LevelWarn, message: "transaction rollback", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "", KeyDuration: "1s", KeyAttempts: "1",
+					KeyReason: ReasonCommitFailed, KeyError: "commit transaction: context canceled",
+				}},
+			},
+		},
+		"error - panic returned as an error": {
+			opts:    []transaction.Option{transaction.WithPanicAsError()},
+			logOpts: []Option{WithSampleRate(0)},
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error { panic("boom") })
+			},
+			expectedRecords: []record{
+				{level: slog.LevelWarn, message: "transaction rollback", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "", KeyDuration: "1s", KeyAttempts: "1",
+					KeyReason: ReasonPanic, KeyError: "panic in transaction: boom",
+				}},
+			},
+		},
+		"error - propagated panic": {
+			logOpts: []Option{WithSampleRate(0)},
+			run: func(ctx context.Context, m transaction.Manager) error {
+				assert.PanicsWithValue(t, "boom", func() {
+					_ = m.ExecTx(ctx, func(ctx context.Context) error { panic("boom") })
+				})
+				return nil
+			},
+			expectedRecords: []record{
+				{level: slog.LevelWarn, message: "transaction rollback", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "", KeyDuration: "1s", KeyAttempts: "1",
+					KeyReason: ReasonPanic,
+				}},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := &captureHandler{}
+			m := newManager(handler, tt.opts, tt.logOpts...)
+
+			_ = tt.run(context.Background(), m)
+
+			assert.Equal(t, tt.expectedRecords, handler.records)
+		})
+	}
+}
+
+func TestManager_Begin(t *testing.T) {
+	tests := map[string]struct {
+		end             func(ctx context.Context, m transaction.Manager) error
+		expectedRecords []record
+	}{
+		"commit": {
+			end: func(ctx context.Context, m transaction.Manager) error { return m.Commit(ctx) },
+			expectedRecords: []record{
+				{level: slog.LevelInfo, message: "transaction commit", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "import", KeyDuration: "1s", KeyAttempts: "1",
+				}},
+			},
+		},
+		"rollback": {
+			end: func(ctx context.Context, m transaction.Manager) error { return m.Rollback(ctx) },
+			expectedRecords: []record{
+				{level: slog.LevelWarn, message: "transaction rollback", attrs: map[string]string{
+					KeyTxID: "tx-1", KeyName: "import", KeyDuration: "1s", KeyAttempts: "1",
+					KeyReason: ReasonExplicit,
+				}},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := &captureHandler{}
+			m := newManager(handler, nil)
+
+			ctx, err := m.Begin(transaction.WithName(context.Background(), "import"))
+			require.NoError(t, err)
+			id, ok := IDFromContext(ctx)
+			require.True(t, ok)
+			assert.Equal(t, "tx-1", id)
+
+			// The savepoint is not logged on its own and keeps the ID of the transaction
+			spCtx, err := m.Begin(ctx)
+			require.NoError(t, err)
+			id, ok = IDFromContext(spCtx)
+			require.True(t, ok)
+			assert.Equal(t, "tx-1", id)
+			require.NoError(t, m.Commit(spCtx))
+
+			require.NoError(t, tt.end(ctx, m))
+			// The begin record is checked by TestManager_Exec
+			assert.Equal(t, tt.expectedRecords, handler.records[1:])
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Package txmetrics measures the transactions of a transaction.Manager
+package txmetrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+)
+
+// Outcome is how a transaction ended
+type Outcome string
+
+const (
+	OutcomeCommit   Outcome = "commit"
+	OutcomeRollback Outcome = "rollback"
+)
+
+// Recorder receives the measurements of the transactions run through Middleware
+// name is the name given to the transaction with transaction.WithName, or "" if there is none
+// Implementations must be safe for concurrent use
+type Recorder interface {
+	// Started is called when a transaction starts, once its first attempt has begun
+	Started(name string)
+
+	// BeginFailed is called when a transaction could not begin, in which case neither Started nor Finished is called
+	BeginFailed(name string)
+
+	// Began is called every time an attempt of the transaction has begun, with how long beginning it took,
+	// see transaction.BeginDurationFromContext
+	// It is not called for the Managers that do not record it, i.e. those not built on transaction.Runner
+	Began(name string, latency time.Duration)
+
+	// Retried is called every time a transaction is run again after a retryable error
+	Retried(name string)
+
+	// Panicked is called when the function of a transaction panicked, whether the panic was propagated
+	// or returned as a *transaction.PanicError
+	Panicked(name string)
+
+	// Finished is called when a started transaction ends, with its outcome and its duration
+	Finished(name string, outcome Outcome, duration time.Duration)
+}
+
+// New returns a Manager measuring the transactions of next with recorder, see Middleware
+func New(next transaction.Manager, recorder Recorder) transaction.Manager {
+	return transaction.Chain(next, Middleware(recorder))
+}
+
+// Middleware returns a transaction.Middleware measuring the transactions run through transaction.Chain with recorder
+//
+// Only new transactions are measured; savepoints and the units of work joining a transaction
+// are part of the transaction they run in
+// A transaction run several times by the retry policy counts once, its duration including every attempt
+// A transaction whose first attempt fails to begin is only recorded with Recorder.BeginFailed
+func Middleware(recorder Recorder) transaction.Middleware {
+	m := &metrics{
+		recorder: recorder,
+		now:      time.Now,
+	}
+	return m.middleware()
+}
+
+// metrics measures transactions with recorder, reading the time with now
+type metrics struct {
+	recorder Recorder
+	now      func() time.Time
+}
+
+// middleware returns a transaction.Middleware measuring the transactions with the recorder and clock of m
+func (m *metrics) middleware() transaction.Middleware {
+	return func(next transaction.ExecFunc) transaction.ExecFunc {
+		return func(ctx context.Context, opts transaction.TxOptions, fn func(ctx context.Context) error) error {
+			if !transaction.StartsTransaction(ctx, opts) {
+				return next(ctx, opts, fn)
+			}
+			return m.exec(ctx, opts, fn, next)
+		}
+	}
+}
+
+// exec runs fn in a new transaction with next and records its measurements
+func (m *metrics) exec(ctx context.Context, opts transaction.TxOptions, fn func(ctx context.Context) error, next transaction.ExecFunc) error {
+	name := transaction.NameFromContext(ctx)
+	start := m.now()
+
+	attempts := 0
+	done := false
+	defer func() {
+		if !done && attempts > 0 {
+			// fn panicked: the panic goes on unchanged, the transaction having been rolled back
+			m.recorder.Panicked(name)
+			m.recorder.Finished(name, OutcomeRollback, m.now().Sub(start))
+		}
+	}()
+
+	err := next(ctx, opts, func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			m.recorder.Started(name)
+		} else {
+			m.recorder.Retried(name)
+		}
+		if latency, ok := transaction.BeginDurationFromContext(ctx); ok {
+			m.recorder.Began(name, latency)
+		}
+		return fn(ctx)
+	})
+	done = true
+
+	if attempts == 0 {
+		// No transaction began, so there is nothing to report as committed or rolled back
+		if err != nil {
+			m.recorder.BeginFailed(name)
+		}
+		return err
+	}
+
+	var panicErr *transaction.PanicError
+	if errors.As(err, &panicErr) {
+		m.recorder.Panicked(name)
+	}
+
+	if err != nil {
+		m.recorder.Finished(name, OutcomeRollback, m.now().Sub(start))
+		return err
+	}
+	m.recorder.Finished(name, OutcomeCommit, m.now().Sub(start))
+	return nil
+}
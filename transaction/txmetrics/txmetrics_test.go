@@ -0,0 +1,267 @@
+package txmetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/memtransaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var errFn = errors.New("fn failed")
+
+// fakeRecorder records the calls it receives as "<name>: <event>"
+type fakeRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *fakeRecorder) record(name, event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, name+": "+event)
+}
+
+func (r *fakeRecorder) Started(name string) { r.record(name, "started") }
+
+func (r *fakeRecorder) BeginFailed(name string) { r.record(name, "begin failed") }
+
+// Began records no latency, which is measured with the real clock
+func (r *fakeRecorder) Began(name string, latency time.Duration) { r.record(name, "began") }
+
+func (r *fakeRecorder) Retried(name string) { r.record(name, "retried") }
+
+func (r *fakeRecorder) Panicked(name string) { r.record(name, "panicked") }
+
+func (r *fakeRecorder) Finished(name string, outcome Outcome, duration time.Duration) {
+	r.record(name, fmt.Sprintf("%s in %v", outcome, duration))
+}
+
+// newManager returns a Manager on top of memtransaction whose clock moves one second per reading
+func newManager(recorder Recorder, opts ...transaction.Option) transaction.Manager {
+	return newManagerOn(memtransaction.New(opts...), recorder)
+}
+
+// newManagerOn is like newManager, on top of next
+func newManagerOn(next transaction.Manager, recorder Recorder) transaction.Manager {
+	m := &metrics{recorder: recorder}
+	var now time.Time
+	m.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return transaction.Chain(next, m.middleware())
+}
+
+func TestManager_Exec(t *testing.T) {
+	retry := transaction.WithRetryPolicy(transaction.RetryPolicy{
+		MaxAttempts: 3,
+		Retryable:   func(err error) bool { return errors.Is(err, errFn) },
+	})
+
+	tests := map[string]struct {
+		opts           []transaction.Option
+		run            func(ctx context.Context, m transaction.Manager) error
+		expectedEvents []string
+	}{
+		"success - committed transaction": {
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error { return nil })
+			},
+			expectedEvents: []string{
+				": started",
+				": began",
+				": commit in 1s",
+			},
+		},
+		"success - named transaction": {
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecReadOnly(transaction.WithName(ctx, "get_user_with_posts"), func(ctx context.Context) error { return nil })
+			},
+			expectedEvents: []string{
+				"get_user_with_posts: started",
+				"get_user_with_posts: began",
+				"get_user_with_posts: commit in 1s",
+			},
+		},
+		"success - retried transaction counts once": {
+			opts: []transaction.Option{retry},
+			run: func(ctx context.Context, m transaction.Manager) error {
+				attempts := 0
+				return m.ExecTx(ctx, func(ctx context.Context) error {
+					attempts++
+					if attempts < 3 {
+						return errFn
+					}
+					return nil
+				})
+			},
+			expectedEvents: []string{
+				": started",
+				": began",
+				": retried",
+				": began",
+				": retried",
+				": began",
+				": commit in 1s",
+			},
+		},
+		"success - savepoints and joined units are part of the transaction": {
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error {
+					if err := m.ExecTx(ctx, func(ctx context.Context) error { return nil }); err != nil {
+						return err
+					}
+					return m.ExecReadOnly(ctx, func(ctx context.Context) error { return nil })
+				})
+			},
+			expectedEvents: []string{
+				": started",
+				": began",
+				": commit in 1s",
+			},
+		},
+		"error - rolled back transaction": {
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error { return errFn })
+			},
+			expectedEvents: []string{
+				": started",
+				": began",
+				": rollback in 1s",
+			},
+		},
+		"error - panic returned as an error": {
+			opts: []transaction.Option{transaction.WithPanicAsError()},
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error { panic("boom") })
+			},
+			expectedEvents: []string{
+				": started",
+				": began",
+				": panicked",
+				": rollback in 1s",
+			},
+		},
+		"error - propagated panic": {
+			run: func(ctx context.Context, m transaction.Manager) error {
+				assert.PanicsWithValue(t, "boom", func() {
+					_ = m.ExecTx(ctx, func(ctx context.Context) error { panic("boom") })
+				})
+				return nil
+			},
+			expectedEvents: []string{
+				": started",
+				": began",
+				": panicked",
+				": rollback in 1s",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &fakeRecorder{}
+			m := newManager(recorder, tt.opts...)
+
+			_ = tt.run(context.Background(), m)
+
+			assert.Equal(t, tt.expectedEvents, recorder.events)
+		})
+	}
+}
+
+func TestManager_Begin(t *testing.T) {
+	tests := map[string]struct {
+		end            func(ctx context.Context, m transaction.Manager) error
+		expectedEvents []string
+	}{
+		"commit": {
+			end: func(ctx context.Context, m transaction.Manager) error { return m.Commit(ctx) },
+			expectedEvents: []string{
+				"import: started",
+				"import: began",
+				"import: commit in 1s",
+			},
+		},
+		"rollback": {
+			end: func(ctx context.Context, m transaction.Manager) error { return m.Rollback(ctx) },
+			expectedEvents: []string{
+				"import: started",
+				"import: began",
+				"import: rollback in 1s",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &fakeRecorder{}
+			m := newManager(recorder)
+
+			ctx, err := m.Begin(transaction.WithName(context.Background(), "import"))
+			require.NoError(t, err)
+
+			// The savepoint is not measured on its own
+			spCtx, err := m.Begin(ctx)
+			require.NoError(t, err)
+			require.NoError(t, m.Commit(spCtx))
+
+			require.NoError(t, tt.end(ctx, m))
+			assert.Equal(t, tt.expectedEvents, recorder.events)
+		})
+	}
+}
+
+func TestManager_BeginFailed(t *testing.T) {
+	errBegin := errors.New("begin failed")
+
+	tests := map[string]struct {
+		setup func(next *mocks.MockManager)
+		run   func(ctx context.Context, m transaction.Manager) error
+	}{
+		"ExecTx": {
+			setup: func(next *mocks.MockManager) {
+				next.EXPECT().ExecTxWithOptions(gomock.Any(), transaction.TxOptions{}, gomock.Any()).Return(errBegin)
+			},
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error {
+					t.Fatal("fn must not run")
+					return nil
+				})
+			},
+		},
+		"Begin": {
+			setup: func(next *mocks.MockManager) {
+				next.EXPECT().BeginWithOptions(gomock.Any(), transaction.TxOptions{}).Return(nil, errBegin)
+			},
+			run: func(ctx context.Context, m transaction.Manager) error {
+				_, err := m.Begin(ctx)
+				return err
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			next := mocks.NewMockManager(gomock.NewController(t))
+			tt.setup(next)
+			recorder := &fakeRecorder{}
+			m := newManagerOn(next, recorder)
+
+			err := tt.run(transaction.WithName(context.Background(), "import"), m)
+
+			assert.ErrorIs(t, err, errBegin)
+			// A transaction that never began is neither started nor rolled back
+			assert.Equal(t, []string{"import: begin failed"}, recorder.events)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Package txprometheus exports the measurements of txmetrics as Prometheus metrics
+package txprometheus
+
+import (
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txmetrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Recorder implements txmetrics.Recorder with the following metrics, labelled by transaction name:
+//   - transaction_started_total
+//   - transaction_begin_failures_total, the transactions that could not begin, which are not counted as started
+//   - transaction_finished_total, also labelled by outcome (commit or rollback)
+//   - transaction_retries_total
+//   - transaction_panics_total
+//   - transaction_duration_seconds, also labelled by outcome
+//   - transaction_begin_duration_seconds, the time spent beginning each attempt of a transaction,
+//     waiting for a pooled connection included
+type Recorder struct {
+	started       *prometheus.CounterVec
+	beginFailures *prometheus.CounterVec
+	finished      *prometheus.CounterVec
+	retries       *prometheus.CounterVec
+	panics        *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	begin         *prometheus.HistogramVec
+}
+
+var _ txmetrics.Recorder = (*Recorder)(nil)
+
+// New creates a new Recorder and registers its metrics with reg, or with prometheus.DefaultRegisterer if reg is nil
+func New(reg prometheus.Registerer) (*Recorder, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	r := &Recorder{
+		started: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "transaction_started_total",
+			Help: "Number of transactions started.",
+		}, []string{"name"}),
+		beginFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "transaction_begin_failures_total",
+			Help: "Number of transactions that could not begin.",
+		}, []string{"name"}),
+		finished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "transaction_finished_total",
+			Help: "Number of transactions committed or rolled back.",
+		}, []string{"name", "outcome"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "transaction_retries_total",
+			Help: "Number of times a transaction was run again after a retryable error.",
+		}, []string{"name"}),
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "transaction_panics_total",
+			Help: "Number of transactions rolled back because their function panicked.",
+		}, []string{"name"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "transaction_duration_seconds",
+			Help:    "Duration of the transactions, retries included.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"name", "outcome"}),
+		begin: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "transaction_begin_duration_seconds",
+			Help:    "Time spent beginning each attempt of a transaction, waiting for a pooled connection included.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"name"}),
+	}
+
+	for _, c := range []prometheus.Collector{r.started, r.beginFailures, r.finished, r.retries, r.panics, r.duration, r.begin} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Started implements txmetrics.Recorder
+func (r *Recorder) Started(name string) {
+	r.started.WithLabelValues(name).Inc()
+}
+
+// BeginFailed implements txmetrics.Recorder
+func (r *Recorder) BeginFailed(name string) {
+	r.beginFailures.WithLabelValues(name).Inc()
+}
+
+// Began implements txmetrics.Recorder
+func (r *Recorder) Began(name string, latency time.Duration) {
+	r.begin.WithLabelValues(name).Observe(latency.Seconds())
+}
+
+// Retried implements txmetrics.Recorder
+func (r *Recorder) Retried(name string) {
+	r.retries.WithLabelValues(name).Inc()
+}
+
+// Panicked implements txmetrics.Recorder
+func (r *Recorder) Panicked(name string) {
+	r.panics.WithLabelValues(name).Inc()
+}
+
+// Finished implements txmetrics.Recorder
+func (r *Recorder) Finished(name string, outcome txmetrics.Outcome, duration time.Duration) {
+	r.finished.WithLabelValues(name, string(outcome)).Inc()
+	r.duration.WithLabelValues(name, string(outcome)).Observe(duration.Seconds())
+}
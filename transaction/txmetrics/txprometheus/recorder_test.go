@@ -0,0 +1,46 @@
+package txprometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txmetrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	r, err := New(reg)
+	require.NoError(t, err)
+
+	r.Started("create_user")
+	r.Began("create_user", 2*time.Millisecond)
+	r.Retried("create_user")
+	r.Finished("create_user", txmetrics.OutcomeCommit, 20*time.Millisecond)
+	r.Started("create_user")
+	r.Began("create_user", time.Millisecond)
+	r.Panicked("create_user")
+	r.Finished("create_user", txmetrics.OutcomeRollback, 10*time.Millisecond)
+	r.BeginFailed("create_user")
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(r.started.WithLabelValues("create_user")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(r.beginFailures.WithLabelValues("create_user")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(r.finished.WithLabelValues("create_user", "commit")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(r.finished.WithLabelValues("create_user", "rollback")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(r.retries.WithLabelValues("create_user")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(r.panics.WithLabelValues("create_user")))
+	assert.Equal(t, 9, testutil.CollectAndCount(reg))
+}
+
+func TestNew_AlreadyRegistered(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := New(reg)
+	require.NoError(t, err)
+
+	_, err = New(reg)
+	var alreadyErr prometheus.AlreadyRegisteredError
+	assert.ErrorAs(t, err, &alreadyErr)
+}
@@ -0,0 +1,47 @@
+// Package txqueries binds sqlc-generated queries to the transaction carried by the context
+package txqueries
+
+import (
+	"context"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+)
+
+// Queries returns the queries of type Q to run in a context
+// D is the DBTX interface of the sqlc package, which both the pool and the transactions started by the Manager satisfy
+type Queries[D any, Q any] struct {
+	base       Q
+	newQueries func(D) Q
+}
+
+// New creates a Queries running on fallback (typically the pool) outside of a transaction
+// newQueries is the New function of the sqlc package, and D its DBTX interface:
+//
+//	queries := txqueries.New[db.DBTX](pool, db.New)
+//	queries := txqueries.New[sqldb.DBTX](sqlDB, sqldb.New)
+func New[D any, Q any](fallback D, newQueries func(D) Q) *Queries[D, Q] {
+	return &Queries[D, Q]{
+		base:       newQueries(fallback),
+		newQueries: newQueries,
+	}
+}
+
+// Bind creates a Queries running base outside of a transaction, and the queries returned by withTx in a transaction
+// It suits the stores given queries instead of a pool, withTx being the WithTx method of the sqlc queries:
+//
+//	queries := txqueries.Bind(q, q.WithTx)
+func Bind[D any, Q any](base Q, withTx func(D) Q) *Queries[D, Q] {
+	return &Queries[D, Q]{
+		base:       base,
+		newQueries: withTx,
+	}
+}
+
+// Get returns the queries bound to the transaction carried by ctx,
+// or the queries running on the fallback when ctx carries no transaction of type D
+func (q *Queries[D, Q]) Get(ctx context.Context) Q {
+	if tx, ok := transaction.TxFromContext[D](ctx); ok {
+		return q.newQueries(tx)
+	}
+	return q.base
+}
@@ -0,0 +1,74 @@
+package txqueries
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/internal/sqlitetest"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/pkg/sqlitedb"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/sqlitetransaction"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	errFn := errors.New("fn failed")
+
+	tests := map[string]struct {
+		fnErr         error
+		expectedFound bool
+	}{
+		"committed transaction keeps the row": {
+			expectedFound: true,
+		},
+		"rolled back transaction drops the row": {
+			fnErr:         errFn,
+			expectedFound: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sqlDB := sqlitetest.NewDB(t)
+			m := sqlitetransaction.New(sqlDB)
+			queries := New[sqlitedb.DBTX](sqlDB, sqlitedb.New)
+			id := uuid.New()
+
+			err := m.ExecTx(context.Background(), func(ctx context.Context) error {
+				assert.NotSame(t, queries.Get(context.Background()), queries.Get(ctx))
+
+				_, err := queries.Get(ctx).CreateUser(ctx, sqlitedb.CreateUserParams{ID: id, Name: "alice", Email: "alice@example.com"})
+				require.NoError(t, err)
+				return tt.fnErr
+			})
+			assert.ErrorIs(t, err, tt.fnErr)
+
+			_, err = queries.Get(context.Background()).GetUser(context.Background(), id)
+			assert.Equal(t, tt.expectedFound, err == nil)
+		})
+	}
+}
+
+func TestGet_WithoutTransaction(t *testing.T) {
+	queries := New[sqlitedb.DBTX](sqlitetest.NewDB(t), sqlitedb.New)
+
+	// The queries running on the fallback are built once
+	assert.Same(t, queries.Get(context.Background()), queries.Get(context.Background()))
+}
+
+func TestBind(t *testing.T) {
+	sqlDB := sqlitetest.NewDB(t)
+	m := sqlitetransaction.New(sqlDB)
+	q := sqlitedb.New(sqlDB)
+	queries := Bind(q, q.WithTx)
+
+	assert.Same(t, q, queries.Get(context.Background()))
+
+	err := m.ExecTx(context.Background(), func(ctx context.Context) error {
+		assert.NotSame(t, q, queries.Get(ctx))
+		return nil
+	})
+	assert.NoError(t, err)
+}
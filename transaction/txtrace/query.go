@@ -0,0 +1,74 @@
+package txtrace
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AttrRowsAffected is the attribute of the query spans holding the number of rows the statement affected
+const AttrRowsAffected = attribute.Key("db.rows_affected")
+
+// sqlcName matches the comment sqlc puts before each query, such as "-- name: GetUser :one"
+var sqlcName = regexp.MustCompile(`^\s*--\s*name:\s*(\w+)`)
+
+// QueryTracer implements pgx.QueryTracer with a span per statement, including the BEGIN, SAVEPOINT
+// and COMMIT statements of the transactions
+// It is set on the pool configuration:
+//
+//	config.ConnConfig.Tracer = txtrace.NewQueryTracer(nil)
+type QueryTracer struct {
+	tracer trace.Tracer
+}
+
+var _ pgx.QueryTracer = (*QueryTracer)(nil)
+
+// NewQueryTracer creates a new QueryTracer with the tracer provider tp, or with the global one if tp is nil
+func NewQueryTracer(tp trace.TracerProvider) *QueryTracer {
+	return &QueryTracer{
+		tracer: tracer(tp),
+	}
+}
+
+// TraceQueryStart starts the span of a statement, named after the sqlc query or the SQL command
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name := queryName(data.SQL)
+	ctx, _ = t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(name),
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd ends the span of a statement, recording its error or the number of rows it affected
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	} else {
+		span.SetAttributes(AttrRowsAffected.Int64(data.CommandTag.RowsAffected()))
+	}
+	span.End()
+}
+
+// queryName returns the name of a sqlc query, or the first keyword of any other statement
+func queryName(sql string) string {
+	if m := sqlcName.FindStringSubmatch(sql); m != nil {
+		return m[1]
+	}
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return "query"
+}
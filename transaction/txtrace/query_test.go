@@ -0,0 +1,65 @@
+package txtrace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestQueryTracer(t *testing.T) {
+	tests := map[string]struct {
+		sql                  string
+		end                  pgx.TraceQueryEndData
+		expectedName         string
+		expectedStatus       codes.Code
+		expectedRowsAffected int64
+	}{
+		"sqlc query - named after the query": {
+			sql:                  "-- name: CreateUser :one\nINSERT INTO users (name, email) VALUES ($1, $2) RETURNING *",
+			end:                  pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("INSERT 0 1")},
+			expectedName:         "CreateUser",
+			expectedStatus:       codes.Unset,
+			expectedRowsAffected: 1,
+		},
+		"transaction statement - named after the command": {
+			sql:            "begin isolation level serializable",
+			end:            pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("BEGIN")},
+			expectedName:   "BEGIN",
+			expectedStatus: codes.Unset,
+		},
+		"failed statement - records the error": {
+			sql:            "-- name: GetUser :one\nSELECT * FROM users WHERE id = $1",
+			end:            pgx.TraceQueryEndData{Err: errors.New("connection reset")},
+			expectedName:   "GetUser",
+			expectedStatus: codes.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tp, exporter := newExporter(t)
+			tracer := NewQueryTracer(tp)
+
+			ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: tt.sql})
+			tracer.TraceQueryEnd(ctx, nil, tt.end)
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			attrs := attributes(span)
+
+			assert.Equal(t, tt.expectedName, span.Name)
+			assert.Equal(t, tt.expectedStatus, span.Status.Code)
+			assert.Equal(t, "postgresql", attrs[semconv.DBSystemKey].AsString())
+			assert.Equal(t, tt.sql, attrs[semconv.DBQueryTextKey].AsString())
+			assert.Equal(t, tt.expectedRowsAffected, attrs[AttrRowsAffected].AsInt64())
+		})
+	}
+}
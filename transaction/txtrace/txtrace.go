@@ -0,0 +1,124 @@
+// Package txtrace traces transactions and the queries run in them with OpenTelemetry
+package txtrace
+
+import (
+	"context"
+	"errors"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans created by this package
+const ScopeName = "github.com/TakumaKurosawa/sqlc-common-transaction/transaction/txtrace"
+
+// Attributes of the transaction spans
+const (
+	AttrIsolation   = attribute.Key("transaction.isolation")
+	AttrPropagation = attribute.Key("transaction.propagation")
+	AttrReadOnly    = attribute.Key("transaction.read_only")
+	AttrAttempts    = attribute.Key("transaction.attempts")
+	AttrOutcome     = attribute.Key("transaction.outcome")
+)
+
+// Values of AttrOutcome
+const (
+	OutcomeCommit   = "commit"
+	OutcomeRollback = "rollback"
+	OutcomePanic    = "panic"
+)
+
+// New returns a Manager tracing the transactions of next with the tracer provider tp,
+// or with the global one if tp is nil, see Middleware
+func New(next transaction.Manager, tp trace.TracerProvider) transaction.Manager {
+	return transaction.Chain(next, Middleware(tp))
+}
+
+// Middleware returns a transaction.Middleware tracing the transactions run through transaction.Chain
+// with a "transaction" span, with the tracer provider tp or with the global one if tp is nil
+//
+// Only new transactions are traced; savepoints and the units of work joining a transaction or running without one
+// are part of the span of the transaction they run in, if any
+//
+// The span lasts from the start of the transaction to its commit or rollback and carries
+// its isolation level, propagation, access mode, number of attempts and outcome
+// The context given to the function, or returned by Begin, carries the span,
+// so that the spans of the stores and queries run in the transaction are its children
+// A transaction rolled back by Rollback is recorded as a rollback, not as an error
+func Middleware(tp trace.TracerProvider) transaction.Middleware {
+	tracer := tracer(tp)
+	return func(next transaction.ExecFunc) transaction.ExecFunc {
+		return func(ctx context.Context, opts transaction.TxOptions, fn func(ctx context.Context) error) error {
+			if !transaction.StartsTransaction(ctx, opts) {
+				return next(ctx, opts, fn)
+			}
+			return exec(ctx, tracer, opts, fn, next)
+		}
+	}
+}
+
+// exec runs fn with next in a new transaction traced by a span, counting the attempts of fn
+func exec(ctx context.Context, tracer trace.Tracer, opts transaction.TxOptions, fn func(ctx context.Context) error, next transaction.ExecFunc) error {
+	ctx, span := tracer.Start(ctx, "transaction", trace.WithAttributes(optionAttributes(opts)...))
+
+	attempts := 0
+	done := false
+	defer func() {
+		if !done {
+			// fn panicked: the panic goes on unchanged, the span only records it
+			span.SetAttributes(AttrAttempts.Int(attempts))
+			span.SetStatus(codes.Error, "panic")
+			end(span, OutcomePanic, nil)
+		}
+	}()
+
+	err := next(ctx, opts, func(ctx context.Context) error {
+		attempts++
+		return fn(ctx)
+	})
+	done = true
+	span.SetAttributes(AttrAttempts.Int(attempts))
+
+	var rollbackErr *transaction.RollbackError
+	switch {
+	case errors.Is(err, transaction.ErrRolledBack) && !errors.As(err, &rollbackErr):
+		end(span, OutcomeRollback, nil)
+	case err != nil:
+		end(span, OutcomeRollback, err)
+	default:
+		end(span, OutcomeCommit, nil)
+	}
+	return err
+}
+
+// optionAttributes returns the span attributes describing opts
+func optionAttributes(opts transaction.TxOptions) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		AttrIsolation.String(opts.Isolation.String()),
+		AttrPropagation.String(opts.Propagation.String()),
+		AttrReadOnly.Bool(opts.ReadOnly),
+	}
+}
+
+// end records the outcome of a transaction and ends its span
+// A failed commit is recorded as a rollback, the transaction being rolled back by the database
+func end(span trace.Span, outcome string, err error) {
+	if err != nil {
+		outcome = OutcomeRollback
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(AttrOutcome.String(outcome))
+	span.End()
+}
+
+// tracer returns the tracer of this package from tp, or from the global provider if tp is nil
+func tracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(ScopeName)
+}
@@ -0,0 +1,259 @@
+package txtrace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usermemorystore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/store/userstore/usertracestore"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction"
+	"github.com/TakumaKurosawa/sqlc-common-transaction/transaction/memtransaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errFn = errors.New("fn failed")
+
+// newExporter returns a tracer provider exporting its spans to the returned in-memory exporter as soon as they end
+func newExporter(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return tp, exporter
+}
+
+// attributes returns the attributes of span as a map
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestManager_Exec(t *testing.T) {
+	tests := map[string]struct {
+		run                 func(ctx context.Context, m transaction.Manager, attempts *int) error
+		expectedIsolation   string
+		expectedPropagation string
+		expectedReadOnly    bool
+		expectedAttempts    int64
+		expectedOutcome     string
+		expectedStatus      codes.Code
+	}{
+		"success - committed transaction": {
+			run: func(ctx context.Context, m transaction.Manager, attempts *int) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error {
+					*attempts++
+					return nil
+				})
+			},
+			expectedIsolation:   "DEFAULT",
+			expectedPropagation: "NESTED",
+			expectedAttempts:    1,
+			expectedOutcome:     OutcomeCommit,
+			expectedStatus:      codes.Unset,
+		},
+		"success - options and retried attempts": {
+			run: func(ctx context.Context, m transaction.Manager, attempts *int) error {
+				opts := transaction.TxOptions{
+					Isolation:   transaction.LevelSerializable,
+					Propagation: transaction.PropagationRequiresNew,
+					Retry: &transaction.RetryPolicy{
+						MaxAttempts: 3,
+						Retryable:   func(err error) bool { return errors.Is(err, errFn) },
+					},
+				}
+				return m.ExecTxWithOptions(ctx, opts, func(ctx context.Context) error {
+					*attempts++
+					if *attempts == 1 {
+						return errFn
+					}
+					return nil
+				})
+			},
+			expectedIsolation:   "SERIALIZABLE",
+			expectedPropagation: "REQUIRES_NEW",
+			expectedAttempts:    2,
+			expectedOutcome:     OutcomeCommit,
+			expectedStatus:      codes.Unset,
+		},
+		"success - read-only transaction": {
+			run: func(ctx context.Context, m transaction.Manager, attempts *int) error {
+				return m.ExecReadOnly(ctx, func(ctx context.Context) error {
+					*attempts++
+					return nil
+				})
+			},
+			expectedIsolation:   "REPEATABLE READ",
+			expectedPropagation: "REQUIRED",
+			expectedReadOnly:    true,
+			expectedAttempts:    1,
+			expectedOutcome:     OutcomeCommit,
+			expectedStatus:      codes.Unset,
+		},
+		"error - rolled back transaction": {
+			run: func(ctx context.Context, m transaction.Manager, attempts *int) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error {
+					*attempts++
+					return errFn
+				})
+			},
+			expectedIsolation:   "DEFAULT",
+			expectedPropagation: "NESTED",
+			expectedAttempts:    1,
+			expectedOutcome:     OutcomeRollback,
+			expectedStatus:      codes.Error,
+		},
+		"error - panicking function": {
+			run: func(ctx context.Context, m transaction.Manager, attempts *int) error {
+				assert.PanicsWithValue(t, "boom", func() {
+					_ = m.ExecTx(ctx, func(ctx context.Context) error {
+						*attempts++
+						panic("boom")
+					})
+				})
+				return nil
+			},
+			expectedIsolation:   "DEFAULT",
+			expectedPropagation: "NESTED",
+			expectedAttempts:    1,
+			expectedOutcome:     OutcomePanic,
+			expectedStatus:      codes.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tp, exporter := newExporter(t)
+			m := New(memtransaction.New(), tp)
+
+			var attempts int
+			_ = tt.run(context.Background(), m, &attempts)
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			attrs := attributes(span)
+
+			assert.Equal(t, "transaction", span.Name)
+			assert.Equal(t, tt.expectedIsolation, attrs[AttrIsolation].AsString())
+			assert.Equal(t, tt.expectedPropagation, attrs[AttrPropagation].AsString())
+			assert.Equal(t, tt.expectedReadOnly, attrs[AttrReadOnly].AsBool())
+			assert.Equal(t, tt.expectedAttempts, attrs[AttrAttempts].AsInt64())
+			assert.Equal(t, int64(attempts), attrs[AttrAttempts].AsInt64())
+			assert.Equal(t, tt.expectedOutcome, attrs[AttrOutcome].AsString())
+			assert.Equal(t, tt.expectedStatus, span.Status.Code)
+		})
+	}
+}
+
+func TestManager_UnitsWithoutTransaction(t *testing.T) {
+	fail := func(context.Context) error { return errFn }
+
+	tests := map[string]struct {
+		run              func(ctx context.Context, m transaction.Manager) error
+		expectedOutcomes []string
+	}{
+		"failed savepoint is part of the committed transaction": {
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error {
+					_ = m.ExecTx(ctx, fail)
+					return nil
+				})
+			},
+			expectedOutcomes: []string{OutcomeCommit},
+		},
+		"failed joined unit is part of the committed transaction": {
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTx(ctx, func(ctx context.Context) error {
+					_ = m.ExecTxWithOptions(ctx, transaction.TxOptions{Propagation: transaction.PropagationRequired}, fail)
+					return nil
+				})
+			},
+			expectedOutcomes: []string{OutcomeCommit},
+		},
+		"failed unit without a transaction is not traced": {
+			run: func(ctx context.Context, m transaction.Manager) error {
+				return m.ExecTxWithOptions(ctx, transaction.TxOptions{Propagation: transaction.PropagationNever}, fail)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tp, exporter := newExporter(t)
+			m := New(memtransaction.New(), tp)
+
+			_ = tt.run(context.Background(), m)
+
+			var outcomes []string
+			for _, span := range exporter.GetSpans() {
+				outcomes = append(outcomes, attributes(span)[AttrOutcome].AsString())
+			}
+			assert.Equal(t, tt.expectedOutcomes, outcomes)
+		})
+	}
+}
+
+func TestManager_Begin(t *testing.T) {
+	tests := map[string]struct {
+		end             func(ctx context.Context, m transaction.Manager) error
+		expectedOutcome string
+	}{
+		"commit": {
+			end:             func(ctx context.Context, m transaction.Manager) error { return m.Commit(ctx) },
+			expectedOutcome: OutcomeCommit,
+		},
+		"rollback": {
+			end:             func(ctx context.Context, m transaction.Manager) error { return m.Rollback(ctx) },
+			expectedOutcome: OutcomeRollback,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tp, exporter := newExporter(t)
+			m := New(memtransaction.New(), tp)
+
+			ctx, err := m.BeginWithOptions(context.Background(), transaction.TxOptions{ReadOnly: true})
+			require.NoError(t, err)
+			assert.Empty(t, exporter.GetSpans(), "the span lasts until the end of the transaction")
+
+			require.NoError(t, tt.end(ctx, m))
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			attrs := attributes(spans[0])
+			assert.Equal(t, "transaction", spans[0].Name)
+			assert.True(t, attrs[AttrReadOnly].AsBool())
+			assert.Equal(t, tt.expectedOutcome, attrs[AttrOutcome].AsString())
+			// Rolling back is not an error
+			assert.Equal(t, codes.Unset, spans[0].Status.Code)
+		})
+	}
+}
+
+func TestManager_StoreSpansAreChildren(t *testing.T) {
+	tp, exporter := newExporter(t)
+	m := New(memtransaction.New(), tp)
+	users := usertracestore.New(usermemorystore.New(), tp)
+
+	err := m.ExecTx(context.Background(), func(ctx context.Context) error {
+		_, err := users.CreateUser(ctx, "alice", "alice@example.com")
+		return err
+	})
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	store, tx := spans[0], spans[1]
+	assert.Equal(t, "userstore.CreateUser", store.Name)
+	assert.Equal(t, "transaction", tx.Name)
+	assert.Equal(t, tx.SpanContext.SpanID(), store.Parent.SpanID())
+}